@@ -2,6 +2,7 @@ package pipe
 
 import (
 	"context"
+	"iter"
 	"sync"
 	"sync/atomic"
 )
@@ -15,35 +16,62 @@ type broadcaster[T any] struct {
 	inCh <-chan T
 	// a channel signaling inCh was closed or broadcaster was detached
 	diedCh chan struct{}
+	// err tells why the broadcaster died, valid after diedCh closed
+	err error
+	// a channel signaling the broadcaster died and all listeners were closed
+	drainedCh chan struct{}
 	// a channel for manipulating listeners.
 	listenerCh chan *listener[T]
+	// a channel for requesting a Stats snapshot
+	statsCh chan chan Stats
+	// received and delivered count values received from inCh and sent to listeners
+	received, delivered atomic.Uint64
 	// activeList holds listeners that have remaining values to flush out
 	activeList listenerList[T]
 	// starvedList holds listeners that is waiting for new values
 	starvedList listenerList[T]
 	// buf stores previously received value
 	buf atomic.Pointer[bufNode[T]]
-	// memorized indicates whether send previous received value
-	// to newly registered listener
-	memorized bool
-	// dedup indicates whether to broadcast newly arrived value
+	// oldest points to the earliest of the latest replay values,
+	// only maintained when replay > 1
+	oldest atomic.Pointer[bufNode[T]]
+	// replay is the number of previously received values to send
+	// to newly registered listener, 0 if not memorized
+	replay int
+	// equal, if not nil, is used to drop newly arrived value
 	// if it is equal to previously arrived value
-	// this should only be set when T is comparable
-	dedup bool
+	equal func(a, b T) bool
+	// bounded indicates whether any bounded listener was ever registered
+	bounded bool
+	// executor runs the select tasks
+	executor Executor
+	// inline allows the loop goroutine to select by itself with few listeners,
+	// bypassing executor, which is only set if executor is the default Pond
+	// and the broadcaster is not traced
+	inline bool
+	// tracer, if not nil, is notified of events of the broadcaster
+	tracer Tracer
+	// lastID is the id of the most recently bound listener, only maintained if traced
+	lastID atomic.Uint64
+	// kind and name describe the broadcaster in the registry
+	kind, name string
+	// unregister removes the broadcaster from the registry
+	unregister func()
 
 	initOnce once
 }
 
-func (b *broadcaster[T]) init(in <-chan T, initial *T) {
+func (b *broadcaster[T]) init(in <-chan T, initial *T, opts []Option[T]) {
 	b.inCh = in
+	b.applyOptions(opts)
 	b.activeList.init()
 	b.starvedList.init()
 	if initial == nil {
 		b.buf.Store(nil)
 	} else {
 		b.buf.Store(&bufNode[T]{value: *initial})
+		b.replay = 1
 	}
-	b.memorized = initial != nil
 }
 
 func (b *broadcaster[T]) initialized() bool {
@@ -53,7 +81,18 @@ func (b *broadcaster[T]) initialized() bool {
 func (b *broadcaster[T]) ensureInit() {
 	b.initOnce.Do(func() {
 		b.diedCh = make(chan struct{})
+		b.drainedCh = make(chan struct{})
 		b.listenerCh = make(chan *listener[T])
+		b.statsCh = make(chan chan Stats)
+		if b.kind == "" {
+			b.kind = "Broadcaster"
+		}
+		b.unregister = register(&node{
+			kind:  b.kind,
+			name:  b.name,
+			stats: b.Stats,
+			reads: func() []chanID { return []chanID{chanIDOf(b.inCh)} },
+		})
 		go b.loop()
 	})
 }
@@ -68,172 +107,172 @@ func (b *broadcaster[T]) doSelect(
 	var listener *_listenerType
 	var value T
 	var ok bool
+	var statsReq chan Stats
 	var recvEntry bool
 	var recvValue bool
 	var nWaiting = 0
+	var executor = b.executor
+	if b.tracer != nil {
+		executor = tracedExecutor{executor, b.tracer}
+	}
 
-	if !isCleaning {
-		nWaiting++
-		selectorPond.tasks <- func() {
-			select {
-			case listener = <-b.listenerCh:
-				recvEntry = true
-			case value, ok = <-b.inCh:
-				recvValue = true
-			case <-barrier:
-			}
-			reply <- selectResult[T]{}
+	if b.inline && b.activeList.n+b.starvedList.n <= inlineMaxListeners {
+		// few listeners, select in the loop goroutine to save handoffs
+		var r selectResult[T]
+		r, listener, value, ok, statsReq, recvEntry, recvValue = b.selectInline(isCleaning)
+		b.handleResult(r, isCleaning)
+	} else {
+		if !isCleaning {
+			nWaiting++
+			executor.Execute(func() {
+				select {
+				case listener = <-b.listenerCh:
+					recvEntry = true
+				case value, ok = <-b.inCh:
+					recvValue = true
+				case statsReq = <-b.statsCh:
+				case <-barrier:
+				}
+				reply <- selectResult[T]{}
+			})
 		}
-	}
-	head := b.activeList.root
-	if head != nil {
-		n := 1
-		p := head
-		activeBuf[0] = p
-	ENUMERATE:
-		for {
-			for n < 8 && p.next != head {
-				p = p.next
-				activeBuf[n] = p
-				n++
-			}
-			switch {
-			case n == 8:
-				b := [8]*_listenerType(activeBuf)
-				selectorPond.tasks <- func() {
-					select8(b, reply, barrier)
+		head := b.activeList.root
+		if head != nil {
+			n := 1
+			p := head
+			activeBuf[0] = p
+		ENUMERATE:
+			for {
+				for n < 8 && p.next != head {
+					p = p.next
+					activeBuf[n] = p
+					n++
 				}
-				nWaiting++
-			default:
-				buf := activeBuf
-				if n >= 4 {
-					b := [4]*_listenerType(activeBuf)
-					buf = buf[4:]
-					selectorPond.tasks <- func() {
-						select4(b, reply, barrier)
-					}
+				switch {
+				case n == 8:
+					b := [8]*_listenerType(activeBuf)
+					executor.Execute(func() {
+						select8(b, reply, barrier)
+					})
 					nWaiting++
-					n -= 4
-				}
+				default:
+					buf := activeBuf
+					if n >= 4 {
+						b := [4]*_listenerType(activeBuf)
+						buf = buf[4:]
+						executor.Execute(func() {
+							select4(b, reply, barrier)
+						})
+						nWaiting++
+						n -= 4
+					}
 
-				if n >= 2 {
-					e0, e1 := buf[0], buf[1]
-					buf = buf[2:]
-					selectorPond.tasks <- func() {
-						select2(e0, e1, reply, barrier)
+					if n >= 2 {
+						e0, e1 := buf[0], buf[1]
+						buf = buf[2:]
+						executor.Execute(func() {
+							select2(e0, e1, reply, barrier)
+						})
+						nWaiting++
+						n -= 2
 					}
-					nWaiting++
-					n -= 2
-				}
 
-				if n == 1 {
-					e := buf[0]
-					selectorPond.tasks <- func() {
-						select1(e, reply, barrier)
+					if n == 1 {
+						e := buf[0]
+						executor.Execute(func() {
+							select1(e, reply, barrier)
+						})
+						nWaiting++
+						n -= 1
 					}
-					nWaiting++
-					n -= 1
-				}
 
-				break ENUMERATE
+					break ENUMERATE
+				}
+				n = 0
 			}
-			n = 0
 		}
-	}
-	head = b.starvedList.root
-	if head != nil {
-		n := 1
-		p := head
-		starvedBuf[0] = p
-	ENUMERATE_STARVE:
-		for {
-			for n < 8 && p.next != head {
-				p = p.next
-				starvedBuf[n] = p
-				n++
-			}
-			switch {
-			case n == 8:
-				b := [8]*_listenerType(starvedBuf)
-				selectorPond.tasks <- func() {
-					selectStarved8(b, reply, barrier)
+		head = b.starvedList.root
+		if head != nil {
+			n := 1
+			p := head
+			starvedBuf[0] = p
+		ENUMERATE_STARVE:
+			for {
+				for n < 8 && p.next != head {
+					p = p.next
+					starvedBuf[n] = p
+					n++
 				}
-				nWaiting++
-			default:
-				buf := starvedBuf
-				if n >= 4 {
-					b := [4]*_listenerType(starvedBuf)
-					buf = buf[4:]
-					selectorPond.tasks <- func() {
-						selectStarved4(b, reply, barrier)
-					}
+				switch {
+				case n == 8:
+					b := [8]*_listenerType(starvedBuf)
+					executor.Execute(func() {
+						selectStarved8(b, reply, barrier)
+					})
 					nWaiting++
-					n -= 4
-				}
+				default:
+					buf := starvedBuf
+					if n >= 4 {
+						b := [4]*_listenerType(starvedBuf)
+						buf = buf[4:]
+						executor.Execute(func() {
+							selectStarved4(b, reply, barrier)
+						})
+						nWaiting++
+						n -= 4
+					}
 
-				if n >= 2 {
-					e0, e1 := buf[0], buf[1]
-					buf = buf[2:]
-					selectorPond.tasks <- func() {
-						selectStarved2(e0, e1, reply, barrier)
+					if n >= 2 {
+						e0, e1 := buf[0], buf[1]
+						buf = buf[2:]
+						executor.Execute(func() {
+							selectStarved2(e0, e1, reply, barrier)
+						})
+						nWaiting++
+						n -= 2
 					}
-					nWaiting++
-					n -= 2
-				}
 
-				if n == 1 {
-					e := buf[0]
-					selectorPond.tasks <- func() {
-						selectStarved1(e, reply, barrier)
+					if n == 1 {
+						e := buf[0]
+						executor.Execute(func() {
+							selectStarved1(e, reply, barrier)
+						})
+						nWaiting++
+						n -= 1
 					}
-					nWaiting++
-					n -= 1
-				}
 
-				break ENUMERATE_STARVE
+					break ENUMERATE_STARVE
+				}
+				n = 0
 			}
-			n = 0
-		}
-	}
-	notResolvedYet := true
-	for nWaiting > 0 {
-		var r selectResult[T]
-		if notResolvedYet {
-			r = <-reply
-			notResolvedYet = false
-			goto HANDLE_REPLY
 		}
-		select {
-		case barrier <- struct{}{}:
-		case r = <-reply:
-			goto HANDLE_REPLY
-		}
-		continue
-	HANDLE_REPLY:
-		switch {
-		case r.dead != nil:
-			if r.starved != nil {
-				b.starvedList.drop(r.dead)
-			} else {
-				b.activeList.drop(r.dead)
+		notResolvedYet := true
+		for nWaiting > 0 {
+			var r selectResult[T]
+			if notResolvedYet {
+				r = <-reply
+				notResolvedYet = false
+				goto HANDLE_REPLY
 			}
-			r.dead.finalize()
-		case r.starved != nil:
-			if isCleaning {
-				b.activeList.drop(r.starved)
-				r.starved.finalize()
-			} else {
-				b.activeList.drop(r.starved)
-				b.starvedList.append(r.starved)
+			select {
+			case barrier <- struct{}{}:
+			case r = <-reply:
+				goto HANDLE_REPLY
 			}
+			continue
+		HANDLE_REPLY:
+			b.handleResult(r, isCleaning)
+			nWaiting--
+			continue
 		}
-		nWaiting--
-		continue
 	}
 	switch {
+	case statsReq != nil:
+		statsReq <- b.snapshot()
 	case recvEntry:
 		if listener == nil {
 			// signal to die
+			b.err = ErrDetached
 			return true
 		}
 		if listener.outCh == nil {
@@ -243,27 +282,61 @@ func (b *broadcaster[T]) doSelect(
 		select {
 		case <-listener.cancelCh:
 			// listener was canceled before registered
+			if b.tracer != nil {
+				b.tracer.Cancel(listener.id)
+			}
 			listener.finalize()
 			return false
 		default:
 		}
-		if b.memorized {
-			listener.buf = b.buf.Load()
+		if b.tracer != nil {
+			b.tracer.Bind(listener.id)
+		}
+		if b.replay > 0 {
+			listener.buf = b.replayHead()
 		}
 		listener.newBuf = &b.buf
-		if listener.buf == nil {
+		listener.delivered = &b.delivered
+		if listener.queue != nil {
+			b.bounded = true
+			// replay at most MaxLag latest values
+			maxLag := uint64(len(listener.queue.items))
+			tail := b.buf.Load()
+			for p := listener.buf; p != nil; p = p.next.Load() {
+				if tail.seq-p.seq < maxLag {
+					listener.queue.push(p)
+				}
+			}
+			listener.buf = nil
+			if listener.queue.len() == 0 {
+				b.starvedList.append(listener)
+			} else {
+				b.activeList.append(listener)
+			}
+		} else if listener.buf == nil {
 			b.starvedList.append(listener)
 		} else {
 			b.activeList.append(listener)
 		}
 	case recvValue:
 		if !ok {
+			b.err = ErrClosed
 			return true
 		}
-		b.replaceBuf(value)
+		b.received.Add(1)
+		if b.tracer != nil {
+			b.tracer.Receive(value)
+		}
+		if stored := b.replaceBuf(value); !stored {
+			// value was dropped as duplicated, starved listeners stay starved
+			return false
+		}
+		if b.bounded {
+			b.feedBounded(b.buf.Load())
+		}
 		// {
 		// 	buf := b.buf.Load()
-		// 	if b.dedup && buf != nil && any(buf.value) == any(value) {
+		// 	if b.equal != nil && buf != nil && b.equal(buf.value, value) {
 		// 		goto DONT_STORE
 		// 	}
 		// 	node := &bufNode[T]{value: value}
@@ -278,22 +351,112 @@ func (b *broadcaster[T]) doSelect(
 	return false
 }
 
-func (b *broadcaster[T]) replaceBuf(value T) {
-	var new *bufNode[T]
-START:
-	old := b.buf.Load()
-	if b.dedup && old != nil && any(old.value) == any(value) {
-		return
+// handleResult updates listener lists according to r, which is replied by a select task.
+func (b *broadcaster[T]) handleResult(r selectResult[T], isCleaning bool) {
+	switch {
+	case r.dead != nil:
+		if b.tracer != nil {
+			b.tracer.Cancel(r.dead.id)
+		}
+		if r.starved != nil {
+			b.starvedList.drop(r.dead)
+		} else {
+			b.activeList.drop(r.dead)
+		}
+		r.dead.finalize()
+	case r.starved != nil:
+		if isCleaning {
+			b.activeList.drop(r.starved)
+			r.starved.finalize()
+		} else {
+			b.activeList.drop(r.starved)
+			b.starvedList.append(r.starved)
+			if b.tracer != nil {
+				b.tracer.Starve(r.starved.id)
+			}
+		}
 	}
-	if new == nil {
-		new = &bufNode[T]{value: value}
+}
+
+// replaceBuf appends value to the buffer list. Besides the loop goroutine, it may be
+// called concurrently by Send of controllers before initialization, so a node is linked
+// by claiming old.next first, and b.buf is swung forward by whoever sees it lagging.
+func (b *broadcaster[T]) replaceBuf(value T) (stored bool) {
+	new := &bufNode[T]{value: value}
+	if b.tracer != nil {
+		new.at = nanotime()
+	}
+	for {
+		old := b.buf.Load()
+		if old == nil {
+			if b.appendFirst(new) {
+				return true
+			}
+			continue
+		}
+		next := old.next.Load()
+		if next == nil {
+			if b.equal != nil && b.equal(old.value, value) {
+				return false
+			}
+			new.seq = old.seq + 1
+			if old.next.CompareAndSwap(nil, new) {
+				b.buf.CompareAndSwap(old, new)
+				break
+			}
+			next = old.next.Load()
+		}
+		// old is no longer the latest, help to swing b.buf and retry
+		b.buf.CompareAndSwap(old, next)
+	}
+	if b.replay > 1 {
+		// advance oldest to the earliest of the latest replay values, unless
+		// another writer has advanced it further
+		for {
+			p := b.oldest.Load()
+			q := p
+			for q.seq+uint64(b.replay) <= new.seq {
+				q = q.next.Load()
+			}
+			if q == p || b.oldest.CompareAndSwap(p, q) {
+				break
+			}
+		}
+	}
+	return true
+}
+
+// appendFirst tries to store new as the first node of the buffer list.
+func (b *broadcaster[T]) appendFirst(new *bufNode[T]) (stored bool) {
+	if b.replay > 1 {
+		// oldest is claimed before b.buf, so that it is never nil when b.buf is not
+		if !b.oldest.CompareAndSwap(nil, new) {
+			b.buf.CompareAndSwap(nil, b.oldest.Load())
+			return false
+		}
+		// either we or a helper stores new
+		b.buf.CompareAndSwap(nil, new)
+		return true
+	}
+	return b.buf.CompareAndSwap(nil, new)
+}
+
+// replayHead returns the earliest value to be replayed to newly registered listener.
+func (b *broadcaster[T]) replayHead() *bufNode[T] {
+	if b.replay <= 1 {
+		return b.buf.Load()
 	}
-	if old != nil {
-		old.next = new
+	// load oldest before tail, so that oldest precedes tail in the list
+	head := b.oldest.Load()
+	tail := b.buf.Load()
+	if tail == nil {
+		return nil
 	}
-	if !b.buf.CompareAndSwap(old, new) {
-		goto START
+	// oldest might lag behind while a writer is advancing it
+	for tail.seq-head.seq >= uint64(b.replay) {
+		head = head.next.Load()
 	}
+	return head
 }
 
 func (b *broadcaster[T]) loop() {
@@ -309,6 +472,9 @@ func (b *broadcaster[T]) loop() {
 		}
 	}
 	close(b.diedCh)
+	if b.tracer != nil {
+		b.tracer.Close(b.err)
+	}
 	{
 		p := b.starvedList.root
 		sentinel := p
@@ -316,6 +482,9 @@ func (b *broadcaster[T]) loop() {
 			goto DONE
 		}
 	LOOP:
+		if b.tracer != nil {
+			b.tracer.Finalize(p.id)
+		}
 		close(p.outCh)
 		p = p.next
 		if p != sentinel {
@@ -331,6 +500,8 @@ func (b *broadcaster[T]) loop() {
 			true)
 	}
 	barrierPool.Put(barrier)
+	close(b.drainedCh)
+	b.unregister()
 }
 
 func (b *broadcaster[T]) current() T {
@@ -338,6 +509,65 @@ func (b *broadcaster[T]) current() T {
 	return buf.value
 }
 
+// memorized returns the latest value if the broadcaster memorizes exactly one value.
+func (b *broadcaster[T]) memorized() (value T, ok bool) {
+	if b.replay != 1 {
+		return value, false
+	}
+	if buf := b.buf.Load(); buf != nil {
+		return buf.value, true
+	}
+	return value, false
+}
+
+func (b *broadcaster[T]) recent() []T {
+	// load head before tail, so that every node before tail is fully linked
+	head := b.replayHead()
+	tail := b.buf.Load()
+	if head == nil || tail == nil {
+		return nil
+	}
+	n := tail.seq - head.seq + 1
+	if n > uint64(b.replay) {
+		n = uint64(b.replay)
+	}
+	values := make([]T, 0, n)
+	for p := head; ; p = p.next.Load() {
+		if tail.seq-p.seq < n {
+			values = append(values, p.value)
+		}
+		if p == tail {
+			break
+		}
+	}
+	return values
+}
+
+// closeErr returns why the broadcaster died, or nil if it is still alive.
+func (b *broadcaster[T]) closeErr() error {
+	if !b.initialized() {
+		return nil
+	}
+	select {
+	case <-b.diedCh:
+		return b.err
+	default:
+		return nil
+	}
+}
+
+// waitDrained blocks until the broadcaster died and every listener has received
+// all pending values or been canceled, or ctx is done.
+func (b *broadcaster[T]) waitDrained(ctx context.Context) error {
+	b.ensureInit()
+	select {
+	case <-b.drainedCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (b *broadcaster[T]) detach() {
 	if !b.initialized() {
 		return
@@ -348,13 +578,23 @@ func (b *broadcaster[T]) detach() {
 	}
 }
 
-func (b *broadcaster[T]) bind(outCh chan<- T, cancelCh <-chan struct{}) (success bool) {
+func (b *broadcaster[T]) bind(outCh chan<- T, cancelCh <-chan struct{}, opts *BindOptions) (success bool) {
 	b.ensureInit()
 	entry := newListener[T]()
 	entry.outCh = outCh
 	entry.cancelCh = cancelCh
+	if b.tracer != nil {
+		entry.tracer = b.tracer
+		entry.id = ListenerID(b.lastID.Add(1))
+	}
+	if opts != nil && opts.MaxLag > 0 {
+		entry.queue = newRingBuf[T](opts.MaxLag)
+		entry.overflow = opts.Overflow
+	}
 	select {
 	case <-b.diedCh:
+		// closes outCh
+		entry.finalize()
 		return false
 	case b.listenerCh <- entry:
 		return true
@@ -366,8 +606,15 @@ func (b *broadcaster[T]) bind(outCh chan<- T, cancelCh <-chan struct{}) (success
 // A canceller is returned for canceling the subscription. When called, out will be
 // unregistered and closed.
 func (b *broadcaster[T]) Bind(out chan<- T) (cancel func()) {
+	return b.BindWithOptions(out, BindOptions{})
+}
+
+// BindWithOptions is similar to Bind, but allows the listener to be bounded.
+// A bounded listener keeps at most opts.MaxLag pending values, and applies
+// opts.Overflow when a new value arrives while it is full.
+func (b *broadcaster[T]) BindWithOptions(out chan<- T, opts BindOptions) (cancel func()) {
 	cancelCh := make(chan struct{})
-	if !b.bind(out, cancelCh) {
+	if !b.bind(out, cancelCh, &opts) {
 		return noop
 	}
 	var once sync.Once
@@ -380,7 +627,7 @@ func (b *broadcaster[T]) Bind(out chan<- T) (cancel func()) {
 
 // BindContext is similar to Bind, but will cancel when ctx is done.
 func (b *broadcaster[T]) BindContext(ctx context.Context, out chan<- T) {
-	b.bind(out, ctx.Done())
+	b.bind(out, ctx.Done(), nil)
 }
 
 // Listen creates a new output channel and registers it as a new listener.
@@ -390,6 +637,17 @@ func (b *broadcaster[T]) Listen() (<-chan T, func()) {
 	return out, b.Bind(out)
 }
 
+// All returns an iterator over subsequent values from the broadcaster.
+// The listener is canceled as soon as the loop ends, see All.
+func (b *broadcaster[T]) All() iter.Seq[T] { return All[T](b) }
+
+// ListenWithOptions is similar to Listen, but registers the listener with opts.
+// See BindWithOptions for details.
+func (b *broadcaster[T]) ListenWithOptions(opts BindOptions) (<-chan T, func()) {
+	out := make(chan T)
+	return out, b.BindWithOptions(out, opts)
+}
+
 // ListenContext is similar to Listen, but will cancel when ctx is done.
 func (b *broadcaster[T]) ListenContext(ctx context.Context) <-chan T {
 	out := make(chan T)
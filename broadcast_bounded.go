@@ -0,0 +1,119 @@
+package pipe
+
+// OverflowPolicy decides what happens when a bounded listener lags behind
+// the upstream by more than BindOptions.MaxLag values.
+type OverflowPolicy uint8
+
+const (
+	// DropOldest discards the oldest pending value to make room for the new one.
+	DropOldest OverflowPolicy = iota
+	// DropNewest discards the newly arrived value, keeping pending ones.
+	DropNewest
+	// SkipToLatest discards all pending values and keeps only the new one.
+	SkipToLatest
+	// Disconnect unregisters the listener and closes its output channel.
+	Disconnect
+)
+
+// BindOptions configures a listener registered by BindWithOptions.
+type BindOptions struct {
+	// MaxLag caps the number of values pending for the listener.
+	// A non-positive MaxLag means unbounded, the same as Bind.
+	MaxLag int
+	// Overflow is the policy applied when MaxLag is exceeded.
+	Overflow OverflowPolicy
+}
+
+// ringBuf is a fixed-capacity FIFO queue backing a bounded listener.
+// It stores copies of buffer nodes, so that the shared buffer list is not retained.
+type ringBuf[T any] struct {
+	items      []bufNode[T]
+	head, size int
+}
+
+func newRingBuf[T any](n int) *ringBuf[T] {
+	return &ringBuf[T]{items: make([]bufNode[T], n)}
+}
+
+func (r *ringBuf[T]) len() int { return r.size }
+
+func (r *ringBuf[T]) full() bool { return r.size == len(r.items) }
+
+func (r *ringBuf[T]) front() *bufNode[T] { return &r.items[r.head] }
+
+func (r *ringBuf[T]) push(node *bufNode[T]) {
+	slot := &r.items[(r.head+r.size)%len(r.items)]
+	*slot = bufNode[T]{value: node.value, seq: node.seq, at: node.at}
+	r.size++
+}
+
+func (r *ringBuf[T]) pop() {
+	r.items[r.head] = bufNode[T]{}
+	r.head = (r.head + 1) % len(r.items)
+	r.size--
+}
+
+func (r *ringBuf[T]) clear() {
+	for r.size > 0 {
+		r.pop()
+	}
+	r.head = 0
+}
+
+// enqueue appends node to a bounded listener, applying its overflow policy.
+// It returns false if the listener should be disconnected.
+func (e *listener[T]) enqueue(node *bufNode[T]) (alive bool) {
+	q := e.queue
+	if !q.full() {
+		q.push(node)
+		return true
+	}
+	switch e.overflow {
+	case DropOldest:
+		q.pop()
+		q.push(node)
+	case DropNewest:
+	case SkipToLatest:
+		q.clear()
+		q.push(node)
+	case Disconnect:
+		return false
+	}
+	return true
+}
+
+// feedBounded pushes a newly arrived node into every bounded listener.
+// It must be called from the loop goroutine, before starvedList is spliced.
+func (b *broadcaster[T]) feedBounded(node *bufNode[T]) {
+	if head := b.starvedList.root; head != nil {
+		p := head
+		for {
+			if p.queue != nil {
+				p.enqueue(node)
+			}
+			p = p.next
+			if p == head {
+				break
+			}
+		}
+	}
+	head := b.activeList.root
+	if head == nil {
+		return
+	}
+	var dead []*listener[T]
+	p := head
+	for {
+		if p.queue != nil && !p.enqueue(node) {
+			dead = append(dead, p)
+		}
+		p = p.next
+		if p == head {
+			break
+		}
+	}
+	for _, e := range dead {
+		b.activeList.drop(e)
+		e.finalize()
+	}
+}
@@ -8,14 +8,27 @@ import (
 
 type bufNode[T any] struct {
 	value T
-	next  *bufNode[T]
+	seq   uint64
+	// at is when the value was received, only set if the broadcaster is traced
+	at int64
+	// next is set once, by the writer appending the following node
+	next atomic.Pointer[bufNode[T]]
 }
 
 type listener[T any] struct {
-	outCh      chan<- T
-	cancelCh   <-chan struct{}
-	buf        *bufNode[T]
-	newBuf     *atomic.Pointer[bufNode[T]]
+	outCh    chan<- T
+	cancelCh <-chan struct{}
+	buf      *bufNode[T]
+	newBuf   *atomic.Pointer[bufNode[T]]
+	// queue is non-nil for bounded listeners, which keep a private copy
+	// of pending values instead of walking the shared buffer list
+	queue    *ringBuf[T]
+	overflow OverflowPolicy
+	// delivered counts values sent to outCh of all listeners of the broadcaster
+	delivered *atomic.Uint64
+	// tracer, if not nil, is notified of events of the listener identified by id
+	tracer     Tracer
+	id         ListenerID
 	prev, next *listener[T]
 }
 
@@ -31,17 +44,26 @@ func newListener[T any]() *listener[T] {
 }
 
 func (e *listener[T]) finalize() {
+	if e.tracer != nil {
+		e.tracer.Finalize(e.id)
+		e.tracer = nil
+	}
 	if e.outCh != nil {
 		close(e.outCh)
 		e.outCh = nil
 	}
 	e.newBuf = nil
 	e.buf = nil
+	e.queue = nil
+	e.delivered = nil
 	e.cancelCh = nil
 	listenerPool.Put((*untypedListener)(unsafe.Pointer(e)))
 }
 
 func (e *listener[T]) curItem() T {
+	if e.queue != nil {
+		return e.queue.front().value
+	}
 	if e.buf == nil {
 		e.buf = e.newBuf.Load()
 	}
@@ -49,19 +71,34 @@ func (e *listener[T]) curItem() T {
 }
 
 func (e *listener[T]) advanceItem() (starved bool) {
-	e.buf = e.buf.next
+	e.delivered.Add(1)
+	if e.queue != nil {
+		if e.tracer != nil {
+			e.traceDeliver(e.queue.front())
+		}
+		e.queue.pop()
+		return e.queue.len() == 0
+	}
+	if e.tracer != nil {
+		e.traceDeliver(e.buf)
+	}
+	e.buf = e.buf.next.Load()
 	return e.buf == nil
 }
 
 type listenerList[T any] struct {
 	root *listener[T]
+	// n is the number of listeners in the list
+	n int
 }
 
 func (l *listenerList[T]) init() {
 	l.root = nil
+	l.n = 0
 }
 
 func (l *listenerList[T]) append(e *listener[T]) {
+	l.n++
 	root := l.root
 	if root == nil {
 		l.root = e
@@ -78,6 +115,7 @@ func (l *listenerList[T]) drop(e *listener[T]) {
 	if e.prev == nil {
 		return
 	}
+	l.n--
 	prev, next := e.prev, e.next
 	if prev == next && next == e {
 		l.root = nil
@@ -94,6 +132,8 @@ func (l *listenerList[T]) spliceTo(l2 *listenerList[T]) {
 	if l.root == nil {
 		return
 	}
+	l2.n += l.n
+	l.n = 0
 	if l2.root == nil {
 		l2.root = l.root
 		l.root = nil
@@ -0,0 +1,51 @@
+package pipe
+
+// An Option configures a broadcaster or a controller of element type T on construction.
+type Option[T any] func(*options[T])
+
+type options[T any] struct {
+	equal    func(a, b T) bool
+	executor Executor
+	tracer   Tracer
+	name     string
+}
+
+// WithEqual makes the broadcaster drop a newly arrived value if it is equal to
+// the previously arrived value according to equal.
+func WithEqual[T any](equal func(a, b T) bool) Option[T] {
+	return func(o *options[T]) { o.equal = equal }
+}
+
+// WithDedup is a shorthand for WithEqual with the == operator.
+func WithDedup[T comparable]() Option[T] {
+	return WithEqual(func(a, b T) bool { return a == b })
+}
+
+// WithExecutor makes the broadcaster run its tasks on e instead of the default executor.
+// This isolates latency-critical broadcasters from busy ones, e.g., by giving them a dedicated Pond.
+func WithExecutor[T any](e Executor) Option[T] {
+	return func(o *options[T]) { o.executor = e }
+}
+
+// WithName names the broadcaster, which identifies it in the registry, see EnableRegistry.
+func WithName[T any](name string) Option[T] {
+	return func(o *options[T]) { o.name = name }
+}
+
+func (b *broadcaster[T]) applyOptions(opts []Option[T]) {
+	var o options[T]
+	for _, opt := range opts {
+		opt(&o)
+	}
+	b.equal = o.equal
+	b.executor = o.executor
+	b.tracer = o.tracer
+	b.name = o.name
+	if b.executor == nil {
+		b.executor = DefaultExecutor()
+		// a custom executor should see every task
+		_, b.inline = b.executor.(*Pond)
+	}
+	// so should Task of the tracer
+	b.inline = b.inline && b.tracer == nil
+}
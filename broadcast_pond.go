@@ -2,48 +2,132 @@ package pipe
 
 import (
 	"runtime"
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
-type pond struct {
-	idleCount atomic.Int32
-	tasks     chan func()
-	dispatch  chan func()
+// An Executor runs tasks submitted by broadcasters, each of which blocks until
+// a channel operation completes. Tasks of a broadcaster depend on each other,
+// so an Executor must eventually run every submitted task concurrently, and
+// should not queue tasks behind a fixed number of workers.
+type Executor interface {
+	Execute(task func())
+}
+
+// PondConfig configures a Pond.
+type PondConfig struct {
+	// MaxPooledWorkers is the maximum number of long-living workers, 0 means unlimited.
+	// It is a soft limit: tasks submitted while all pooled workers are busy run on transient
+	// goroutines, since queueing them could deadlock broadcasters, see Executor.
+	MaxPooledWorkers int
+	// IdleTimeout is the interval at which idle workers are reaped. Defaults to 5 seconds.
+	IdleTimeout time.Duration
+	// ReapRatio is the ratio of idle workers to all workers, below which reaping stops.
+	// Defaults to 0.5.
+	ReapRatio float64
 }
 
-var selectorPond = newPond()
+// A Pond is an Executor that reuses worker goroutines. It is the default executor
+// of broadcasters.
+type Pond struct {
+	config    PondConfig
+	idleCount atomic.Int32
+	// workers is the number of live pooled workers
+	workers  atomic.Int32
+	tasks    chan func()
+	dispatch chan func()
+
+	closeOnce sync.Once
+	closeCh   chan struct{}
+}
 
-func newPond() *pond {
-	b := new(pond)
+// NewPond returns a Pond configured by config.
+func NewPond(config PondConfig) *Pond {
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = 5 * time.Second
+	}
+	if config.ReapRatio <= 0 {
+		config.ReapRatio = 0.5
+	}
+	b := new(Pond)
+	b.config = config
 	b.tasks = make(chan func())
 	b.dispatch = make(chan func())
+	b.closeCh = make(chan struct{})
 	go b.loop()
 	return b
 }
 
-func (b *pond) loop() {
+// Execute runs task on an idle worker, or a new goroutine if no worker is idle.
+// After Close, every task runs on a new goroutine.
+func (b *Pond) Execute(task func()) {
+	select {
+	case b.tasks <- task:
+	case <-b.closeCh:
+		go task()
+	}
+}
+
+// Workers returns the number of live pooled workers, which never exceeds MaxPooledWorkers
+// if set. Transient goroutines are not counted.
+func (b *Pond) Workers() int { return int(b.workers.Load()) }
+
+// Close stops b, and lets its workers exit after their running tasks.
+// Broadcasters still using b will run their tasks on new goroutines.
+// It is safe to call Close multiple times.
+func (b *Pond) Close() {
+	b.closeOnce.Do(func() { close(b.closeCh) })
+}
+
+var defaultExecutor atomic.Pointer[Executor]
+
+func init() { SetDefaultExecutor(NewPond(PondConfig{})) }
+
+// SetDefaultExecutor sets the executor of broadcasters created afterwards without WithExecutor.
+func SetDefaultExecutor(e Executor) { defaultExecutor.Store(&e) }
+
+// DefaultExecutor returns the executor set by SetDefaultExecutor, which is a Pond
+// with default config initially.
+func DefaultExecutor() Executor { return *defaultExecutor.Load() }
+
+func (b *Pond) loop() {
 	var ticker *time.Ticker
 	var tickerC <-chan time.Time
 	var cancelC chan func()
 	var total int32
+	spawn := func(task func()) {
+		if b.config.MaxPooledWorkers > 0 && int(total) >= b.config.MaxPooledWorkers {
+			go task()
+			return
+		}
+		total += 1
+		b.workers.Add(1)
+		go b.worker(task)
+	}
 	for {
 		select {
+		case <-b.closeCh:
+			if ticker != nil {
+				ticker.Stop()
+			}
+			// idle workers exit, and busy ones after their tasks
+			close(b.dispatch)
+			return
 		case task := <-b.tasks:
 			if ticker == nil {
-				ticker = time.NewTicker(time.Second * 5)
+				ticker = time.NewTicker(b.config.IdleTimeout)
 				tickerC = ticker.C
 			}
 			if b.idleCount.Load() == 0 {
-				total += 1
-				go b.worker(task)
+				spawn(task)
 				continue
 			}
 			fails := 0
 		INNER:
 			for {
 				if fails == 10 {
-					go b.worker(task)
+					spawn(task)
 					break INNER
 				}
 				select {
@@ -64,7 +148,7 @@ func (b *pond) loop() {
 			}
 		case cancelC <- nil:
 			total -= 1
-			if b.idleCount.Load() < total/2 {
+			if float64(b.idleCount.Load()) < float64(total)*b.config.ReapRatio {
 				cancelC = nil
 			}
 		}
@@ -72,7 +156,8 @@ func (b *pond) loop() {
 
 }
 
-func (b *pond) worker(task func()) {
+func (b *Pond) worker(task func()) {
+	defer b.workers.Add(-1)
 	task()
 	b.idleCount.Add(1)
 	for task = range b.dispatch {
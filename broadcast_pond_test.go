@@ -0,0 +1,128 @@
+package pipe_test
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hsfzxjy/pipe"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingExecutor runs each task on a new goroutine and counts tasks.
+type countingExecutor struct{ n atomic.Int32 }
+
+func (e *countingExecutor) Execute(task func()) {
+	e.n.Add(1)
+	go task()
+}
+
+func broadcastTo(t *testing.T, c *pipe.Controller[int], nListeners int) {
+	var outs []<-chan int
+	for i := 0; i < nListeners; i++ {
+		out, cancel := c.Listen()
+		defer cancel()
+		outs = append(outs, out)
+	}
+	for i := 0; i < 3; i++ {
+		c.Send(i)
+		for _, out := range outs {
+			assert.Equal(t, i, <-out)
+		}
+	}
+}
+
+func TestWithExecutor(t *testing.T) {
+	var e countingExecutor
+	c := pipe.NewController[int](pipe.WithExecutor[int](&e))
+	broadcastTo(t, c, 10)
+	assert.Positive(t, e.n.Load())
+}
+
+// watchWorkers records the high-water mark of p.Workers() until stop is closed.
+func watchWorkers(p *pipe.Pond, stop <-chan struct{}) *atomic.Int32 {
+	var peak atomic.Int32
+	go func() {
+		for {
+			if n := int32(p.Workers()); n > peak.Load() {
+				peak.Store(n)
+			}
+			select {
+			case <-stop:
+				return
+			default:
+				runtime.Gosched()
+			}
+		}
+	}()
+	return &peak
+}
+
+func TestPondMaxPooledWorkers(t *testing.T) {
+	p := pipe.NewPond(pipe.PondConfig{
+		MaxPooledWorkers: 2,
+		IdleTimeout:      10 * time.Millisecond,
+		ReapRatio:        1,
+	})
+	defer p.Close()
+	stop := make(chan struct{})
+	peak := watchWorkers(p, stop)
+	// listeners span several select groups, which would deadlock if they were
+	// queued behind the pooled workers
+	c := pipe.NewController[int](pipe.WithExecutor[int](p))
+	broadcastTo(t, c, 40)
+	// let idle workers be reaped
+	time.Sleep(50 * time.Millisecond)
+	broadcastTo(t, c, 40)
+	close(stop)
+	assert.Positive(t, peak.Load())
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+}
+
+func TestPondClose(t *testing.T) {
+	p := pipe.NewPond(pipe.PondConfig{})
+	c := pipe.NewController[int](pipe.WithExecutor[int](p))
+	broadcastTo(t, c, 10)
+	assert.Positive(t, p.Workers())
+	p.Close()
+	p.Close()
+	// tasks run on new goroutines after closing, and workers exit after their tasks
+	broadcastTo(t, c, 10)
+	eventually(t, func() bool { return p.Workers() == 0 })
+}
+
+func TestSetDefaultExecutor(t *testing.T) {
+	old := pipe.DefaultExecutor()
+	defer pipe.SetDefaultExecutor(old)
+	var e countingExecutor
+	pipe.SetDefaultExecutor(&e)
+	assert.Same(t, &e, pipe.DefaultExecutor())
+	c := pipe.NewController[int]()
+	broadcastTo(t, c, 10)
+	assert.Positive(t, e.n.Load())
+}
+
+func TestInlineDelivery(t *testing.T) {
+	p := pipe.NewPond(pipe.PondConfig{})
+	defer p.Close()
+	old := pipe.DefaultExecutor()
+	defer pipe.SetDefaultExecutor(old)
+	pipe.SetDefaultExecutor(p)
+	c := pipe.NewController[int]()
+	// at most 3 listeners are served by the loop goroutine itself
+	broadcastTo(t, c, 3)
+	assert.Zero(t, p.Workers())
+
+	// the 4th listener switches back to the executor
+	broadcastTo(t, c, 4)
+	assert.Positive(t, p.Workers())
+}
+
+func TestWithExecutorFewListeners(t *testing.T) {
+	var e countingExecutor
+	c := pipe.NewController[int](pipe.WithExecutor[int](&e))
+	// a custom executor is never bypassed
+	broadcastTo(t, c, 1)
+	assert.Positive(t, e.n.Load())
+}
@@ -216,3 +216,79 @@ func select8[T any](entries [8]*listener[T], reply chan<- selectResult[T], barri
 	}
 
 }
+
+// inlineMaxListeners is the maximum number of listeners, with which the loop goroutine
+// performs the select itself instead of shipping select tasks to the default Pond.
+const inlineMaxListeners = 3
+
+// selectInline performs the select of doSelect in the current goroutine,
+// given that the broadcaster has at most inlineMaxListeners listeners.
+func (b *broadcaster[T]) selectInline(isCleaning bool) (
+	r selectResult[T],
+	entry *listener[T], value T, ok bool,
+	statsReq chan Stats,
+	recvEntry, recvValue bool,
+) {
+	var entries [inlineMaxListeners]*listener[T]
+	var cancelChs [inlineMaxListeners]<-chan struct{}
+	var outChs [inlineMaxListeners]chan<- T
+	var items [inlineMaxListeners]T
+	n := 0
+	for _, l := range [2]*listenerList[T]{&b.activeList, &b.starvedList} {
+		head := l.root
+		if head == nil {
+			continue
+		}
+		for p := head; ; {
+			entries[n] = p
+			cancelChs[n] = p.cancelCh
+			if l == &b.activeList {
+				outChs[n] = p.outCh
+				items[n] = p.curItem()
+			}
+			n++
+			if p = p.next; p == head {
+				break
+			}
+		}
+	}
+	listenerCh, inCh, statsCh := b.listenerCh, b.inCh, b.statsCh
+	if isCleaning {
+		listenerCh, inCh, statsCh = nil, nil, nil
+	}
+
+	var i int
+	var sent bool
+	select {
+	case entry = <-listenerCh:
+		recvEntry = true
+		return
+	case value, ok = <-inCh:
+		recvValue = true
+		return
+	case statsReq = <-statsCh:
+		return
+	case <-cancelChs[0]:
+		i = 0
+	case outChs[0] <- items[0]:
+		i, sent = 0, true
+	case <-cancelChs[1]:
+		i = 1
+	case outChs[1] <- items[1]:
+		i, sent = 1, true
+	case <-cancelChs[2]:
+		i = 2
+	case outChs[2] <- items[2]:
+		i, sent = 2, true
+	}
+	e := entries[i]
+	switch {
+	case !sent && outChs[i] == nil:
+		r = selectResult[T]{dead: e, starved: e}
+	case !sent:
+		r = selectResult[T]{dead: e}
+	case e.advanceItem():
+		r = selectResult[T]{starved: e}
+	}
+	return
+}
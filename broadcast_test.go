@@ -44,6 +44,18 @@ func closed[T any](ch <-chan T) func() bool {
 	}
 }
 
+// received returns a condition that holds once a value is received from ch.
+func received[T any](ch <-chan T) func() bool {
+	return func() bool {
+		select {
+		case _, ok := <-ch:
+			return ok
+		default:
+			return false
+		}
+	}
+}
+
 func TestBroadcastNonBlocking(t *testing.T) {
 	ch := make(chan int)
 	b := pipe.Broadcast(ch)
@@ -203,3 +215,105 @@ func TestBroadcastCMUntilChCancel(t *testing.T) {
 	go cancel()
 	nonblocking(t, func() { <-wait })
 }
+
+func TestBroadcastBindWithOptions(t *testing.T) {
+	cases := []struct {
+		policy pipe.OverflowPolicy
+		want   []int
+	}{
+		{pipe.DropOldest, []int{4, 5}},
+		{pipe.DropNewest, []int{1, 2}},
+		{pipe.SkipToLatest, []int{5}},
+	}
+	for _, c := range cases {
+		ch := make(chan int)
+		b := pipe.Broadcast(ch)
+		out, _ := b.ListenWithOptions(pipe.BindOptions{MaxLag: 2, Overflow: c.policy})
+		// sync is drained first, so that all values are queued before reading out
+		sync, _ := b.Listen()
+		go func() {
+			for i := 1; i <= 5; i++ {
+				ch <- i
+			}
+			close(ch)
+		}()
+		for range sync {
+		}
+		var got []int
+		for x := range out {
+			got = append(got, x)
+		}
+		assert.Equal(t, c.want, got, "policy=%d", c.policy)
+	}
+}
+
+func TestBroadcastBindWithOptionsDisconnect(t *testing.T) {
+	ch := make(chan int)
+	b := pipe.Broadcast(ch)
+	defer b.Detach()
+	out, _ := b.ListenWithOptions(pipe.BindOptions{MaxLag: 2, Overflow: pipe.Disconnect})
+	out2, cancel2 := b.Listen()
+	defer cancel2()
+	for i := 1; i <= 3; i++ {
+		ch <- i
+	}
+	eventually(t, closed(out))
+	assert.Equal(t, 1, <-out2)
+}
+
+func TestBroadcastMBindWithOptions(t *testing.T) {
+	ch := make(chan int)
+	b := pipe.BroadcastM(ch, 42)
+	out, _ := b.ListenWithOptions(pipe.BindOptions{MaxLag: 1, Overflow: pipe.DropOldest})
+	sync, _ := b.Listen()
+	assert.Equal(t, 42, <-sync)
+	go func() {
+		ch <- 1
+		ch <- 2
+		close(ch)
+	}()
+	for range sync {
+	}
+	assert.Equal(t, 2, <-out)
+	_, ok := <-out
+	assert.False(t, ok)
+}
+
+func TestBroadcastRBind(t *testing.T) {
+	ch := make(chan int)
+	b := pipe.BroadcastR(ch, 3)
+	assert.Empty(t, b.Recent())
+	out, cancel := b.Listen()
+	defer cancel()
+	for i := 1; i <= 5; i++ {
+		ch <- i
+		assert.Equal(t, i, <-out)
+	}
+	assert.Equal(t, []int{3, 4, 5}, b.Recent())
+	out2, cancel2 := b.Listen()
+	defer cancel2()
+	assert.Equal(t, 3, <-out2)
+	assert.Equal(t, 4, <-out2)
+	assert.Equal(t, 5, <-out2)
+	ch <- 6
+	assert.Equal(t, 6, <-out2)
+	close(ch)
+}
+
+func TestBroadcastCMWithDedup(t *testing.T) {
+	ch := make(chan int)
+	b := pipe.BroadcastCM(ch, 42, pipe.WithDedup[int]())
+	out, _ := b.Listen()
+	assert.Equal(t, 42, <-out)
+	go func() {
+		ch <- 42
+		ch <- 1
+		ch <- 1
+		ch <- 2
+		close(ch)
+	}()
+	assert.Equal(t, 1, <-out)
+	assert.Equal(t, 2, <-out)
+	_, ok := <-out
+	assert.False(t, ok)
+}
@@ -2,6 +2,8 @@ package pipe
 
 import (
 	"context"
+	"fmt"
+	"iter"
 
 	"golang.org/x/exp/slices"
 )
@@ -12,19 +14,31 @@ type Listenable[T any] interface {
 	Listen() (out <-chan T, cancel func())
 }
 
+// An Iterable can be ranged over with range-over-func, see All.
+// Broadcasters, controllers, derived operators and subscriptions are Iterable.
+type Iterable[T any] interface {
+	All() iter.Seq[T]
+}
+
 // A listenable object that also memorizes the latest value.
 type ListenableM[T any] interface {
 	Listenable[T]
 	Current() T
 }
 
+// A listenable object that also memorizes the latest N values.
+type ListenableR[T any] interface {
+	Listenable[T]
+	Recent() []T
+}
+
 // A listenable object with comparable element type.
 // This allows additional methods Until, UntilCh and UntilContext to be called.
 type ListenableC[T comparable] interface {
 	Listenable[T]
-	Until(...T)
-	UntilCh(...T) (<-chan struct{}, func())
-	UntilContext(context.Context, ...T)
+	Until(...T) (T, error)
+	UntilCh(...T) (<-chan UntilResult[T], func())
+	UntilContext(context.Context, ...T) (T, error)
 }
 
 // A listenable object with comparable element type and memorizes the latest value.
@@ -34,55 +48,29 @@ type ListenableCM[T comparable] interface {
 }
 
 // Until blocks until one of the conditions satisfies:
-// 1) one of the value from b shows up in targets;
-// 2) b does not accept new listeners (either b is detached or upstream channel closed).
-func Until[T comparable, P Listenable[T]](b P, targets ...T) {
-	out, cancel := b.Listen()
-	for x := range out {
-		if slices.Contains(targets, x) {
-			cancel()
-			return
-		}
-	}
+// 1) one of the value from b shows up in targets, which is returned with a nil error;
+// 2) b does not accept new listeners, in which case ErrDetached or ErrClosed is returned.
+// If b memorizes the latest value, it is checked first.
+func Until[T comparable, P Listenable[T]](b P, targets ...T) (T, error) {
+	return UntilFunc[T](b, func(x T) bool { return slices.Contains(targets, x) })
 }
 
 // UntilCh is the asynchronous version of Until.
-// The returned signalCh will be closed when one of the conditions satisfies:
+// The returned resultCh yields exactly one result and then closes, when one of the conditions satisfies:
 // 1) one of the value from b shows up in targets;
-// 2) b does not accept new listeners (either b is detached or upstream channel closed);
-// 3) canceller is called.
-func UntilCh[T comparable, P Listenable[T]](b P, targets ...T) (signalCh <-chan struct{}, canceller func()) {
-	out, cancel := b.Listen()
-	signal := make(chan struct{})
-	go func() {
-		defer close(signal)
-		defer cancel()
-		for x := range out {
-			if slices.Contains(targets, x) {
-				return
-			}
-		}
-	}()
-	return signal, cancel
+// 2) b does not accept new listeners, in which case Err is ErrDetached or ErrClosed;
+// 3) canceller is called, in which case Err is ErrCancelled.
+func UntilCh[T comparable, P Listenable[T]](b P, targets ...T) (resultCh <-chan UntilResult[T], canceller func()) {
+	return UntilFuncCh[T](b, func(x T) bool { return slices.Contains(targets, x) })
 }
 
 // UntilContext blocks until one of the conditions satisfies:
-// 1) one of the value from b shows up in targets;
-// 2) b does not accept new listeners (either b is detached or upstream channel closed);
-// 3) ctx is canceled.
-func UntilContext[T comparable, P Listenable[T]](ctx context.Context, b P, targets ...T) {
-	out, cancel := b.Listen()
-	defer cancel()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case x, ok := <-out:
-			if !ok || slices.Contains(targets, x) {
-				return
-			}
-		}
-	}
+// 1) one of the value from b shows up in targets, which is returned with a nil error;
+// 2) b does not accept new listeners, in which case ErrDetached or ErrClosed is returned;
+// 3) ctx is done, in which case ctx.Err() is returned.
+// If b memorizes the latest value, it is checked first.
+func UntilContext[T comparable, P Listenable[T]](ctx context.Context, b P, targets ...T) (T, error) {
+	return UntilFuncContext[T](ctx, b, func(x T) bool { return slices.Contains(targets, x) })
 }
 
 type detachableBroadcaster[T any] struct{ broadcaster[T] }
@@ -97,9 +85,10 @@ type Broadcaster[T any] struct{ detachableBroadcaster[T] }
 // Broadcast returns a Broadcaster that pipes values from upstream channel into listeners.
 // Broadcaster gaurantees upstream <- val from outside will NOT block, but if it's detached
 // prematurely, upstream <- val will block again.
-func Broadcast[T any](upstream <-chan T) *Broadcaster[T] {
+func Broadcast[T any](upstream <-chan T, opts ...Option[T]) *Broadcaster[T] {
 	b := new(Broadcaster[T])
-	b.init(upstream, nil)
+	b.init(upstream, nil, opts)
+	b.kind = "Broadcast"
 	b.ensureInit()
 	return b
 }
@@ -110,9 +99,10 @@ type BroadcasterM[T any] struct{ detachableBroadcaster[T] }
 // Newly registered listener will be firstly fed with the memorized latest value, then subsequent values from upstream.
 // If no value coming out of upstream yet, initial is fed.
 // The latest value is stored by value (instead of by reference).
-func BroadcastM[T any](upstream <-chan T, initial T) *BroadcasterM[T] {
+func BroadcastM[T any](upstream <-chan T, initial T, opts ...Option[T]) *BroadcasterM[T] {
 	b := new(BroadcasterM[T])
-	b.init(upstream, &initial)
+	b.init(upstream, &initial, opts)
+	b.kind = "BroadcastM"
 	b.ensureInit()
 	return b
 }
@@ -120,21 +110,41 @@ func BroadcastM[T any](upstream <-chan T, initial T) *BroadcasterM[T] {
 // Current returns the latest value that the broadcaster memorizes.
 func (b *BroadcasterM[T]) Current() T { return b.current() }
 
+type BroadcasterR[T any] struct{ detachableBroadcaster[T] }
+
+// BroadcastR returns a broadcaster that memorizes the latest n values from upstream.
+// Newly registered listener will be firstly fed with the memorized values in arriving order,
+// then subsequent values from upstream. BroadcastR panics if n < 1.
+func BroadcastR[T any](upstream <-chan T, n int, opts ...Option[T]) *BroadcasterR[T] {
+	if n < 1 {
+		panic(fmt.Sprintf("expect n >= 1 for BroadcastR, got %d", n))
+	}
+	b := new(BroadcasterR[T])
+	b.init(upstream, nil, opts)
+	b.kind = "BroadcastR"
+	b.replay = n
+	b.ensureInit()
+	return b
+}
+
+// Recent returns at most n latest values that the broadcaster memorizes, from oldest to newest.
+func (b *BroadcasterR[T]) Recent() []T { return b.recent() }
+
 type broadcasterc[T comparable] struct{ broadcaster[T] }
 
 // Shorthand for Until(b, targets...)
-func (b *broadcasterc[T]) Until(targets ...T) {
-	Until(b, targets...)
+func (b *broadcasterc[T]) Until(targets ...T) (T, error) {
+	return Until[T](b, targets...)
 }
 
 // Shorthand for UntilCh(b, targets...)
-func (b *broadcasterc[T]) UntilCh(targets ...T) (<-chan struct{}, func()) {
-	return UntilCh(b, targets...)
+func (b *broadcasterc[T]) UntilCh(targets ...T) (<-chan UntilResult[T], func()) {
+	return UntilCh[T](b, targets...)
 }
 
 // Shorthand for UntilContext(ctx, b, targets...)
-func (b *broadcasterc[T]) UntilContext(ctx context.Context, targets ...T) {
-	UntilContext(ctx, b, targets...)
+func (b *broadcasterc[T]) UntilContext(ctx context.Context, targets ...T) (T, error) {
+	return UntilContext[T](ctx, b, targets...)
 }
 
 type detachableBroadcasterC[T comparable] struct{ broadcasterc[T] }
@@ -149,9 +159,10 @@ type BroadcasterC[T comparable] struct{ broadcasterc[T] }
 // BroadcastC returns a broadcaster with a comparable type T as element type.
 // This allows methods like b.Until(targets...) to be called instead of Until(b, targets...),
 // which helps auto type inference and sometimes saves the typing of type variables.
-func BroadcastC[T comparable](in <-chan T) *BroadcasterC[T] {
+func BroadcastC[T comparable](in <-chan T, opts ...Option[T]) *BroadcasterC[T] {
 	b := new(BroadcasterC[T])
-	b.init(in, nil)
+	b.init(in, nil, opts)
+	b.kind = "BroadcastC"
 	b.ensureInit()
 	return b
 }
@@ -160,9 +171,10 @@ type BroadcasterCM[T comparable] struct{ detachableBroadcasterC[T] }
 
 // BroadcastCM returns a broadcaster with comparable element type and also
 // is able to memorize the latest value.
-func BroadcastCM[T comparable](in <-chan T, initial T) *BroadcasterCM[T] {
+func BroadcastCM[T comparable](in <-chan T, initial T, opts ...Option[T]) *BroadcasterCM[T] {
 	b := new(BroadcasterCM[T])
-	b.init(in, &initial)
+	b.init(in, &initial, opts)
+	b.kind = "BroadcastCM"
 	b.ensureInit()
 	return b
 }
@@ -0,0 +1,96 @@
+package pipe
+
+import "time"
+
+// A Clock creates timers and tickers for time-based operators.
+// It can be replaced for deterministic testing, see WithClock.
+type Clock interface {
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// A Timer fires once on C after the duration it is created with.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// A Ticker fires on C periodically.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// RealClock is the Clock backed by package time.
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) NewTimer(d time.Duration) Timer   { return realTimer{time.NewTimer(d)} }
+func (realClock) NewTicker(d time.Duration) Ticker { return realTicker{time.NewTicker(d)} }
+
+type realTimer struct{ *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.Timer.C }
+
+type realTicker struct{ *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// A TimeOption configures a time-based operator.
+type TimeOption func(*timeOptions)
+
+type timeOptions struct {
+	clock    Clock
+	leading  bool
+	trailing bool
+}
+
+func newTimeOptions(opts []TimeOption) timeOptions {
+	o := timeOptions{clock: RealClock, leading: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithClock makes the operator use c instead of RealClock.
+func WithClock(c Clock) TimeOption {
+	return func(o *timeOptions) { o.clock = c }
+}
+
+// WithLeading sets whether Throttle emits the first value of each window. Defaults to true.
+func WithLeading(leading bool) TimeOption {
+	return func(o *timeOptions) { o.leading = leading }
+}
+
+// WithTrailing sets whether Throttle emits the latest value at the end of each window. Defaults to false.
+func WithTrailing(trailing bool) TimeOption {
+	return func(o *timeOptions) { o.trailing = trailing }
+}
+
+// timer wraps a Timer that may be absent, in which case C is nil.
+type timer struct {
+	t Timer
+	C <-chan time.Time
+}
+
+func (t *timer) start(clock Clock, d time.Duration) {
+	t.stop()
+	t.t = clock.NewTimer(d)
+	t.C = t.t.C()
+}
+
+func (t *timer) stop() {
+	if t.t != nil {
+		t.t.Stop()
+		t.fired()
+	}
+}
+
+// fired marks the timer as absent after receiving from C.
+func (t *timer) fired() {
+	t.t, t.C = nil, nil
+}
+
+func (t *timer) active() bool { return t.t != nil }
@@ -1,6 +1,37 @@
 package pipe
 
-type sink[T any] struct{ ch chan T }
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type sink[T any] struct {
+	ch chan T
+	// mu guards ch against closing while sending
+	mu     sync.RWMutex
+	closed bool
+}
+
+// send sends value to ch, it returns false if ch was closed.
+func (s *sink[T]) send(value T) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return false
+	}
+	s.ch <- value
+	return true
+}
+
+func (s *sink[T]) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
 
 // A Controller bundles a sink channel and a broadcaster.
 type Controller[T any] struct {
@@ -8,10 +39,11 @@ type Controller[T any] struct {
 	broadcaster[T]
 }
 
-func NewController[T any]() *Controller[T] {
+func NewController[T any](opts ...Option[T]) *Controller[T] {
 	c := new(Controller[T])
 	c.sink.ch = make(chan T)
-	c.broadcaster.init(c.sink.ch, nil)
+	c.broadcaster.init(c.sink.ch, nil, opts)
+	c.kind = "Controller"
 	return c
 }
 
@@ -21,11 +53,25 @@ func (c *Controller[T]) Sink() chan<- T {
 	return c.ch
 }
 
-// Send sends value to the sink channel.
+// Close closes the sink channel, after which Send returns false.
+// It is safe to call Close multiple times, but the sink channel should not be closed elsewhere.
+func (c *Controller[T]) Close() {
+	c.ensureInit()
+	c.close()
+}
+
+// CloseAndWait closes the controller, and waits until every listener has received
+// all pending values or been canceled. If ctx is done before that, ctx.Err() is returned.
+func (c *Controller[T]) CloseAndWait(ctx context.Context) error {
+	c.Close()
+	return c.waitDrained(ctx)
+}
+
+// Send sends value to the sink channel. It returns false if the controller was closed,
+// or not initialized yet.
 func (c *Controller[T]) Send(value T) (ok bool) {
 	if c.initialized() {
-		c.ch <- value
-		return true
+		return c.send(value)
 	}
 	return false
 }
@@ -36,10 +82,11 @@ type ControllerC[T comparable] struct {
 	broadcasterc[T]
 }
 
-func NewControllerC[T comparable]() *ControllerC[T] {
+func NewControllerC[T comparable](opts ...Option[T]) *ControllerC[T] {
 	c := new(ControllerC[T])
 	c.sink.ch = make(chan T)
-	c.broadcaster.init(c.sink.ch, nil)
+	c.broadcaster.init(c.sink.ch, nil, opts)
+	c.kind = "ControllerC"
 	return c
 }
 
@@ -49,11 +96,25 @@ func (c *ControllerC[T]) Sink() chan<- T {
 	return c.ch
 }
 
-// Send sends value to the sink channel.
+// Close closes the sink channel, after which Send returns false.
+// It is safe to call Close multiple times, but the sink channel should not be closed elsewhere.
+func (c *ControllerC[T]) Close() {
+	c.ensureInit()
+	c.close()
+}
+
+// CloseAndWait closes the controller, and waits until every listener has received
+// all pending values or been canceled. If ctx is done before that, ctx.Err() is returned.
+func (c *ControllerC[T]) CloseAndWait(ctx context.Context) error {
+	c.Close()
+	return c.waitDrained(ctx)
+}
+
+// Send sends value to the sink channel. It returns false if the controller was closed,
+// or not initialized yet.
 func (c *ControllerC[T]) Send(value T) (ok bool) {
 	if c.initialized() {
-		c.ch <- value
-		return true
+		return c.send(value)
 	}
 	return false
 }
@@ -64,10 +125,11 @@ type ControllerM[T any] struct {
 	broadcaster[T]
 }
 
-func NewControllerM[T any](initial T) *ControllerM[T] {
+func NewControllerM[T any](initial T, opts ...Option[T]) *ControllerM[T] {
 	c := new(ControllerM[T])
 	c.sink.ch = make(chan T)
-	c.broadcaster.init(c.sink.ch, &initial)
+	c.broadcaster.init(c.sink.ch, &initial, opts)
+	c.kind = "ControllerM"
 	return c
 }
 
@@ -77,11 +139,25 @@ func (c *ControllerM[T]) Sink() chan<- T {
 	return c.ch
 }
 
-// Send sends value to the sink channel.
+// Close closes the sink channel, after which Send returns false.
+// It is safe to call Close multiple times, but the sink channel should not be closed elsewhere.
+func (c *ControllerM[T]) Close() {
+	c.ensureInit()
+	c.close()
+}
+
+// CloseAndWait closes the controller, and waits until every listener has received
+// all pending values or been canceled. If ctx is done before that, ctx.Err() is returned.
+func (c *ControllerM[T]) CloseAndWait(ctx context.Context) error {
+	c.Close()
+	return c.waitDrained(ctx)
+}
+
+// Send sends value to the sink channel. It returns false if the controller was closed,
+// or not initialized yet.
 func (c *ControllerM[T]) Send(value T) (ok bool) {
 	if c.initialized() {
-		c.ch <- value
-		return true
+		return c.send(value)
 	}
 	c.replaceBuf(value)
 	return false
@@ -90,17 +166,72 @@ func (c *ControllerM[T]) Send(value T) (ok bool) {
 // Current returns the latest value that the broadcaster memorizes.
 func (c *ControllerM[T]) Current() T { return c.broadcaster.current() }
 
+// A Controller with a broadcaster memorizing the latest N values.
+type ControllerR[T any] struct {
+	sink[T]
+	broadcaster[T]
+}
+
+// NewControllerR panics if n < 1.
+func NewControllerR[T any](n int, opts ...Option[T]) *ControllerR[T] {
+	if n < 1 {
+		panic(fmt.Sprintf("expect n >= 1 for NewControllerR, got %d", n))
+	}
+	c := new(ControllerR[T])
+	c.sink.ch = make(chan T)
+	c.broadcaster.init(c.sink.ch, nil, opts)
+	c.kind = "ControllerR"
+	c.replay = n
+	return c
+}
+
+// Sink returns the sink channel of the controller.
+func (c *ControllerR[T]) Sink() chan<- T {
+	c.ensureInit()
+	return c.ch
+}
+
+// Close closes the sink channel, after which Send returns false.
+// It is safe to call Close multiple times, but the sink channel should not be closed elsewhere.
+func (c *ControllerR[T]) Close() {
+	c.ensureInit()
+	c.close()
+}
+
+// CloseAndWait closes the controller, and waits until every listener has received
+// all pending values or been canceled. If ctx is done before that, ctx.Err() is returned.
+func (c *ControllerR[T]) CloseAndWait(ctx context.Context) error {
+	c.Close()
+	return c.waitDrained(ctx)
+}
+
+// Send sends value to the sink channel. It returns false if the controller was closed,
+// or not initialized yet.
+func (c *ControllerR[T]) Send(value T) (ok bool) {
+	if c.initialized() {
+		return c.send(value)
+	}
+	c.replaceBuf(value)
+	return false
+}
+
+// Recent returns at most n latest values that the broadcaster memorizes, from oldest to newest.
+func (c *ControllerR[T]) Recent() []T { return c.broadcaster.recent() }
+
 type ControllerCM[T comparable] struct {
 	sink[T]
 	broadcasterc[T]
 }
 
 // A Controller with a comparable element type and memorizable broadcaster.
-func NewControllerCM[T comparable](initial T, dedup bool) *ControllerCM[T] {
+func NewControllerCM[T comparable](initial T, dedup bool, opts ...Option[T]) *ControllerCM[T] {
 	c := new(ControllerCM[T])
 	c.sink.ch = make(chan T)
-	c.broadcaster.init(c.sink.ch, &initial)
-	c.dedup = dedup
+	if dedup {
+		opts = append([]Option[T]{WithDedup[T]()}, opts...)
+	}
+	c.broadcaster.init(c.sink.ch, &initial, opts)
+	c.kind = "ControllerCM"
 	return c
 }
 
@@ -110,11 +241,25 @@ func (c *ControllerCM[T]) Sink() chan<- T {
 	return c.ch
 }
 
-// Send sends value to the sink channel.
+// Close closes the sink channel, after which Send returns false.
+// It is safe to call Close multiple times, but the sink channel should not be closed elsewhere.
+func (c *ControllerCM[T]) Close() {
+	c.ensureInit()
+	c.close()
+}
+
+// CloseAndWait closes the controller, and waits until every listener has received
+// all pending values or been canceled. If ctx is done before that, ctx.Err() is returned.
+func (c *ControllerCM[T]) CloseAndWait(ctx context.Context) error {
+	c.Close()
+	return c.waitDrained(ctx)
+}
+
+// Send sends value to the sink channel. It returns false if the controller was closed,
+// or not initialized yet.
 func (c *ControllerCM[T]) Send(value T) (ok bool) {
 	if c.initialized() {
-		c.ch <- value
-		return true
+		return c.send(value)
 	}
 	c.replaceBuf(value)
 	return false
@@ -1,10 +1,14 @@
 package pipe_test
 
 import (
+	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/hsfzxjy/pipe"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slices"
 )
 
 func TestController(t *testing.T) {
@@ -75,3 +79,117 @@ func TestControllerCMDedup(t *testing.T) {
 	_, ok := <-l
 	assert.False(t, ok)
 }
+
+func TestControllerR(t *testing.T) {
+	c := pipe.NewControllerR[string](2)
+	assert.False(t, c.Send("noop"))
+	assert.Equal(t, []string{"noop"}, c.Recent())
+	l, _ := c.Listen()
+	c.Sink() <- "foo"
+	assert.True(t, c.Send("bar"))
+	assert.Equal(t, "noop", <-l)
+	assert.Equal(t, "foo", <-l)
+	assert.Equal(t, "bar", <-l)
+	assert.Equal(t, []string{"foo", "bar"}, c.Recent())
+	l2, _ := c.Listen()
+	close(c.Sink())
+	assert.Equal(t, "foo", <-l2)
+	assert.Equal(t, "bar", <-l2)
+	_, ok := <-l2
+	assert.False(t, ok)
+}
+
+func TestControllerRConcurrentSend(t *testing.T) {
+	c := pipe.NewControllerR[int](3)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Send(i*100 + j)
+			}
+		}()
+	}
+	wg.Wait()
+	recent := c.Recent()
+	assert.Len(t, recent, 3)
+	l, _ := c.Listen()
+	close(c.Sink())
+	var replayed []int
+	for x := range l {
+		replayed = append(replayed, x)
+	}
+	assert.Equal(t, recent, replayed)
+}
+
+func TestControllerMWithEqual(t *testing.T) {
+	type state struct{ tags []string }
+	c := pipe.NewControllerM(state{}, pipe.WithEqual(func(a, b state) bool {
+		return slices.Equal(a.tags, b.tags)
+	}))
+	l, _ := c.Listen()
+	assert.Empty(t, (<-l).tags)
+	c.Sink() <- state{[]string{"foo"}}
+	c.Sink() <- state{[]string{"foo"}}
+	c.Sink() <- state{[]string{"foo", "bar"}}
+	close(c.Sink())
+	assert.Equal(t, []string{"foo"}, (<-l).tags)
+	assert.Equal(t, []string{"foo", "bar"}, (<-l).tags)
+	_, ok := <-l
+	assert.False(t, ok)
+}
+
+func TestControllerClose(t *testing.T) {
+	c := pipe.NewController[int]()
+	l, _ := c.Listen()
+	assert.True(t, c.Send(1))
+	c.Close()
+	c.Close()
+	assert.False(t, c.Send(2))
+	assert.Equal(t, 1, <-l)
+	eventually(t, closed(l))
+}
+
+func TestControllerCloseConcurrentSend(t *testing.T) {
+	c := pipe.NewControllerM(0)
+	c.Sink()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Send(j)
+			}
+		}()
+	}
+	c.Close()
+	wg.Wait()
+	assert.False(t, c.Send(1))
+}
+
+func TestControllerCloseAndWait(t *testing.T) {
+	c := pipe.NewControllerC[int]()
+	slow, _ := c.Listen()
+	for i := 1; i <= 3; i++ {
+		c.Send(i)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	// slow has pending values
+	assert.ErrorIs(t, c.CloseAndWait(ctx), context.DeadlineExceeded)
+
+	var result []int
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for x := range slow {
+			result = append(result, x)
+		}
+	}()
+	assert.NoError(t, c.CloseAndWait(context.Background()))
+	<-done
+	assert.Equal(t, []int{1, 2, 3}, result)
+}
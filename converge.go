@@ -1,78 +1,110 @@
 package pipe
 
 import (
+	"container/heap"
+	"context"
 	"fmt"
 	"reflect"
 )
 
 // Converge2 converges values from ch1 and ch2 into returned channel.
 func Converge2[A, B any](ch1 <-chan A, ch2 <-chan B) <-chan any {
+	return Converge2Context(context.Background(), ch1, ch2)
+}
+
+// Converge2Context is similar to Converge2, but stops forwarding and closes
+// the returned channel as soon as ctx is done.
+func Converge2Context[A, B any](ctx context.Context, ch1 <-chan A, ch2 <-chan B) <-chan any {
 	out := make(chan any)
+	done := ctx.Done()
+	unregister := registerGoroutine("Converge2", []chanID{chanIDOf(ch1), chanIDOf(ch2)}, []chanID{chanIDOf(out)})
 	go func() {
+		defer unregister()
 		defer close(out)
 		n := 2
 		for n > 0 {
+			var x any
 			select {
-			case x, ok := <-ch1:
+			case <-done:
+				return
+			case x1, ok := <-ch1:
 				if !ok {
 					n--
 					ch1 = nil
-				} else {
-					out <- x
+					continue
 				}
-			case x, ok := <-ch2:
+				x = x1
+			case x2, ok := <-ch2:
 				if !ok {
 					n--
 					ch2 = nil
-				} else {
-					out <- x
+					continue
 				}
+				x = x2
+			}
+			select {
+			case out <- x:
+			case <-done:
+				return
 			}
 		}
 	}()
 	return out
 }
 
-// Converge2 converges values from ch1, ch3 and ch2 into returned channel.
+// Converge3 converges values from ch1, ch2 and ch3 into returned channel.
 func Converge3[A, B, C any](ch1 <-chan A, ch2 <-chan B, ch3 <-chan C) <-chan any {
+	return Converge3Context(context.Background(), ch1, ch2, ch3)
+}
+
+// Converge3Context is similar to Converge3, but stops forwarding and closes
+// the returned channel as soon as ctx is done.
+func Converge3Context[A, B, C any](ctx context.Context, ch1 <-chan A, ch2 <-chan B, ch3 <-chan C) <-chan any {
 	out := make(chan any)
+	done := ctx.Done()
+	unregister := registerGoroutine("Converge3", []chanID{chanIDOf(ch1), chanIDOf(ch2), chanIDOf(ch3)}, []chanID{chanIDOf(out)})
 	go func() {
+		defer unregister()
 		defer close(out)
 		n := 3
 		for n > 0 {
+			var x any
 			select {
-			case x, ok := <-ch1:
+			case <-done:
+				return
+			case x1, ok := <-ch1:
 				if !ok {
 					n--
 					ch1 = nil
-				} else {
-					out <- x
+					continue
 				}
-			case x, ok := <-ch2:
+				x = x1
+			case x2, ok := <-ch2:
 				if !ok {
 					n--
 					ch2 = nil
-				} else {
-					out <- x
+					continue
 				}
-			case x, ok := <-ch3:
+				x = x2
+			case x3, ok := <-ch3:
 				if !ok {
 					n--
 					ch3 = nil
-				} else {
-					out <- x
+					continue
 				}
+				x = x3
+			}
+			select {
+			case out <- x:
+			case <-done:
+				return
 			}
-
 		}
 	}()
 	return out
 }
 
-// ConvergeN converges values from arbitary number of channels.
-// Each of chans should be of type <-chan T for some T.
-func ConvergeN(chans ...any) <-chan any {
-	out := make(chan any)
+func selectCases(chans []any) []reflect.SelectCase {
 	cases := make([]reflect.SelectCase, len(chans))
 	for i, ch := range chans {
 		value := reflect.ValueOf(ch)
@@ -85,7 +117,66 @@ func ConvergeN(chans ...any) <-chan any {
 			Dir:  reflect.SelectRecv,
 		}
 	}
+	return cases
+}
+
+// ConvergeN converges values from arbitary number of channels.
+// Each of chans should be of type <-chan T for some T.
+func ConvergeN(chans ...any) <-chan any {
+	return ConvergeNContext(context.Background(), chans...)
+}
+
+// ConvergeNContext is similar to ConvergeN, but stops forwarding and closes
+// the returned channel as soon as ctx is done.
+func ConvergeNContext(ctx context.Context, chans ...any) <-chan any {
+	out := make(chan any)
+	// the last case receives from ctx.Done()
+	cases := append(selectCases(chans), reflect.SelectCase{
+		Chan: reflect.ValueOf(ctx.Done()),
+		Dir:  reflect.SelectRecv,
+	})
+	done := ctx.Done()
+	unregister := registerGoroutine("ConvergeN", chanIDsOf(chans), []chanID{chanIDOf(out)})
+	go func() {
+		defer unregister()
+		defer close(out)
+		n := len(chans)
+		for n > 0 {
+			i, x, ok := reflect.Select(cases)
+			if i == len(chans) {
+				return
+			}
+			if !ok {
+				n--
+				cases[i].Chan = reflect.Zero(cases[i].Chan.Type())
+				continue
+			}
+			select {
+			case out <- x.Interface():
+			case <-done:
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Tagged is a value from the Index-th input of ConvergeNTagged.
+// If Closed is set, the input has closed and Value is nil.
+type Tagged struct {
+	Index  int
+	Value  any
+	Closed bool
+}
+
+// ConvergeNTagged is similar to ConvergeN, but tags each value with the index of its input.
+// When an input closes, a Tagged with Closed set is emitted.
+func ConvergeNTagged(chans ...any) <-chan Tagged {
+	out := make(chan Tagged)
+	cases := selectCases(chans)
+	unregister := registerGoroutine("ConvergeNTagged", chanIDsOf(chans), []chanID{chanIDOf(out)})
 	go func() {
+		defer unregister()
 		defer close(out)
 		n := len(cases)
 		for n > 0 {
@@ -93,10 +184,211 @@ func ConvergeN(chans ...any) <-chan any {
 			if !ok {
 				n--
 				cases[i].Chan = reflect.Zero(cases[i].Chan.Type())
+				out <- Tagged{Index: i, Closed: true}
+				continue
+			}
+			out <- Tagged{Index: i, Value: x.Interface()}
+		}
+	}()
+	return out
+}
+
+// Either is a value from one of the inputs of Converge2Either.
+// Left is set if Index is 0, otherwise Right is set.
+// If Closed is set, the input has closed and neither is set.
+type Either[A, B any] struct {
+	Index  int
+	Left   A
+	Right  B
+	Closed bool
+}
+
+// Converge2Either is similar to Converge2, but preserves the element types and
+// tells which input each value comes from.
+// When an input closes, an Either with Closed set is emitted.
+func Converge2Either[A, B any](ch1 <-chan A, ch2 <-chan B) <-chan Either[A, B] {
+	out := make(chan Either[A, B])
+	unregister := registerGoroutine("Converge2Either", []chanID{chanIDOf(ch1), chanIDOf(ch2)}, []chanID{chanIDOf(out)})
+	go func() {
+		defer unregister()
+		defer close(out)
+		n := 2
+		for n > 0 {
+			select {
+			case x, ok := <-ch1:
+				if !ok {
+					n--
+					ch1 = nil
+					out <- Either[A, B]{Index: 0, Closed: true}
+				} else {
+					out <- Either[A, B]{Index: 0, Left: x}
+				}
+			case x, ok := <-ch2:
+				if !ok {
+					n--
+					ch2 = nil
+					out <- Either[A, B]{Index: 1, Closed: true}
+				} else {
+					out <- Either[A, B]{Index: 1, Right: x}
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// pollCase tries to receive from cases[i] without blocking.
+// ready is false if the receive would block.
+func pollCase(cases []reflect.SelectCase, i int) (x reflect.Value, ok, ready bool) {
+	x, ok = cases[i].Chan.TryRecv()
+	return x, ok, x.IsValid()
+}
+
+// ConvergePriority is similar to ConvergeN, but when several channels are ready,
+// the one with the smallest index in chans always wins.
+// Each of chans should be of type <-chan T for some T.
+func ConvergePriority(chans ...any) <-chan any {
+	out := make(chan any)
+	cases := selectCases(chans)
+	unregister := registerGoroutine("ConvergePriority", chanIDsOf(chans), []chanID{chanIDOf(out)})
+	go func() {
+		defer unregister()
+		defer close(out)
+		n := len(cases)
+		for n > 0 {
+			var i int
+			var x reflect.Value
+			var ok, ready bool
+			for i = range cases {
+				if x, ok, ready = pollCase(cases, i); ready {
+					break
+				}
+			}
+			if !ready {
+				i, x, ok = reflect.Select(cases)
+			}
+			if !ok {
+				n--
+				cases[i].Chan = reflect.Zero(cases[i].Chan.Type())
+				continue
+			}
+			out <- x.Interface()
+		}
+	}()
+	return out
+}
+
+// ConvergeWeighted is similar to ConvergeN, but serves ready channels in weighted
+// round-robin order: chans[i] may emit up to weights[i] values in a row before the
+// next ready channel is served.
+// Each of chans should be of type <-chan T for some T. ConvergeWeighted panics if
+// len(weights) != len(chans), or any of weights is not positive.
+func ConvergeWeighted(weights []int, chans ...any) <-chan any {
+	if len(weights) != len(chans) {
+		panic(fmt.Sprintf("expect %d weights, got %d", len(chans), len(weights)))
+	}
+	for i, w := range weights {
+		if w <= 0 {
+			panic(fmt.Sprintf("expect a positive weight for %d-th channel, got %d", i, w))
+		}
+	}
+	out := make(chan any)
+	cases := selectCases(chans)
+	unregister := registerGoroutine("ConvergeWeighted", chanIDsOf(chans), []chanID{chanIDOf(out)})
+	go func() {
+		defer unregister()
+		defer close(out)
+		n := len(cases)
+		// cur is the channel being served, which may emit credit more values
+		cur, credit := 0, 0
+		if n > 0 {
+			credit = weights[0]
+		}
+		next := func() {
+			cur = (cur + 1) % len(cases)
+			credit = weights[cur]
+		}
+		for n > 0 {
+			var i int
+			var x reflect.Value
+			var ok, ready bool
+			for k := 0; k < len(cases); k++ {
+				if x, ok, ready = pollCase(cases, cur); ready {
+					break
+				}
+				next()
+			}
+			if ready {
+				i = cur
+			} else {
+				i, x, ok = reflect.Select(cases)
+				cur, credit = i, weights[i]
+			}
+			if !ok {
+				n--
+				cases[i].Chan = reflect.Zero(cases[i].Chan.Type())
+				next()
 				continue
 			}
+			if credit--; credit == 0 {
+				next()
+			}
 			out <- x.Interface()
 		}
 	}()
 	return out
 }
+
+type sortedHead[T any] struct {
+	value T
+	ch    <-chan T
+}
+
+// sortedHeap is a min-heap of the head values of inputs of MergeSorted.
+type sortedHeap[T any] struct {
+	heads []sortedHead[T]
+	less  func(a, b T) bool
+}
+
+func (h *sortedHeap[T]) Len() int           { return len(h.heads) }
+func (h *sortedHeap[T]) Less(i, j int) bool { return h.less(h.heads[i].value, h.heads[j].value) }
+func (h *sortedHeap[T]) Swap(i, j int)      { h.heads[i], h.heads[j] = h.heads[j], h.heads[i] }
+func (h *sortedHeap[T]) Push(x any)         { h.heads = append(h.heads, x.(sortedHead[T])) }
+func (h *sortedHeap[T]) Pop() any {
+	n := len(h.heads) - 1
+	x := h.heads[n]
+	h.heads[n] = sortedHead[T]{}
+	h.heads = h.heads[:n]
+	return x
+}
+
+// MergeSorted merges values from chans into the returned channel in the order defined by less,
+// given that values from each of chans are sorted. It performs a k-way merge, so a value is
+// emitted only after every open input has a pending value. The returned channel will be
+// closed after all of chans closed.
+func MergeSorted[T any](less func(a, b T) bool, chans ...<-chan T) <-chan T {
+	out := make(chan T)
+	unregister := registerGoroutine("MergeSorted", chanIDsOf(chans), []chanID{chanIDOf(out)})
+	go func() {
+		defer unregister()
+		defer close(out)
+		h := &sortedHeap[T]{less: less}
+		for _, ch := range chans {
+			if x, ok := <-ch; ok {
+				h.heads = append(h.heads, sortedHead[T]{x, ch})
+			}
+		}
+		heap.Init(h)
+		for h.Len() > 0 {
+			head := &h.heads[0]
+			out <- head.value
+			if x, ok := <-head.ch; ok {
+				head.value = x
+				heap.Fix(h, 0)
+			} else {
+				heap.Pop(h)
+			}
+		}
+	}()
+	return out
+}
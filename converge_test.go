@@ -1,6 +1,7 @@
 package pipe_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/hsfzxjy/pipe"
@@ -70,3 +71,134 @@ func TestConverge0(t *testing.T) {
 	_, ok := <-r
 	assert.False(t, ok)
 }
+
+func TestConvergeNTagged(t *testing.T) {
+	a := make(chan int)
+	b := make(chan int)
+	r := pipe.ConvergeNTagged(a, b)
+	go func() {
+		a <- 1
+		b <- 1
+		close(a)
+		close(b)
+	}()
+	assert.Equal(t, pipe.Tagged{Index: 0, Value: 1}, <-r)
+	assert.Equal(t, pipe.Tagged{Index: 1, Value: 1}, <-r)
+	assert.ElementsMatch(t, []pipe.Tagged{
+		{Index: 0, Closed: true},
+		{Index: 1, Closed: true},
+	}, []pipe.Tagged{<-r, <-r})
+	_, ok := <-r
+	assert.False(t, ok)
+}
+
+func TestConverge2Either(t *testing.T) {
+	a := make(chan int)
+	b := make(chan string)
+	r := pipe.Converge2Either(a, b)
+	go func() {
+		a <- 42
+		b <- "foo"
+		close(a)
+		close(b)
+	}()
+	assert.Equal(t, pipe.Either[int, string]{Index: 0, Left: 42}, <-r)
+	assert.Equal(t, pipe.Either[int, string]{Index: 1, Right: "foo"}, <-r)
+	assert.ElementsMatch(t, []pipe.Either[int, string]{
+		{Index: 0, Closed: true},
+		{Index: 1, Closed: true},
+	}, []pipe.Either[int, string]{<-r, <-r})
+	_, ok := <-r
+	assert.False(t, ok)
+}
+
+func TestConvergePriority(t *testing.T) {
+	data := make(chan int, 3)
+	shutdown := make(chan string, 1)
+	for i := 0; i < 3; i++ {
+		data <- i
+	}
+	shutdown <- "stop"
+	close(data)
+	close(shutdown)
+	r := pipe.ConvergePriority(shutdown, data)
+	var got []any
+	for x := range r {
+		got = append(got, x)
+	}
+	assert.Equal(t, []any{"stop", 0, 1, 2}, got)
+}
+
+func TestConvergeWeighted(t *testing.T) {
+	a := make(chan string, 4)
+	b := make(chan string, 2)
+	for i := 0; i < 4; i++ {
+		a <- "a"
+	}
+	b <- "b"
+	b <- "b"
+	close(a)
+	close(b)
+	r := pipe.ConvergeWeighted([]int{2, 1}, a, b)
+	var got []any
+	for x := range r {
+		got = append(got, x)
+	}
+	assert.Equal(t, []any{"a", "a", "b", "a", "a", "b"}, got)
+}
+
+func TestMergeSorted(t *testing.T) {
+	feed := func(xs ...int) <-chan int {
+		ch := make(chan int)
+		go func() {
+			defer close(ch)
+			for _, x := range xs {
+				ch <- x
+			}
+		}()
+		return ch
+	}
+	r := pipe.MergeSorted(
+		func(a, b int) bool { return a < b },
+		feed(1, 4, 7, 10),
+		feed(),
+		feed(2, 2, 8),
+		feed(3, 5, 6, 9, 11, 12),
+	)
+	var result []int
+	for x := range r {
+		result = append(result, x)
+	}
+	assert.Equal(t, []int{1, 2, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, result)
+
+	_, ok := <-pipe.MergeSorted(func(a, b int) bool { return a < b })
+	assert.False(t, ok)
+}
+
+func TestConvergeContext(t *testing.T) {
+	for name, converge := range map[string]func(context.Context, chan int, chan string) <-chan any{
+		"Converge2Context": func(ctx context.Context, a chan int, b chan string) <-chan any {
+			return pipe.Converge2Context(ctx, a, b)
+		},
+		"Converge3Context": func(ctx context.Context, a chan int, b chan string) <-chan any {
+			return pipe.Converge3Context(ctx, a, b, make(chan float32))
+		},
+		"ConvergeNContext": func(ctx context.Context, a chan int, b chan string) <-chan any {
+			return pipe.ConvergeNContext(ctx, a, b)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			a := make(chan int)
+			b := make(chan string)
+			ctx, cancel := context.WithCancel(context.Background())
+			r := converge(ctx, a, b)
+			a <- 42
+			assert.Equal(t, 42, <-r)
+			// the consumer stops reading, leaving "foo" pending
+			b <- "foo"
+			cancel()
+			eventually(t, closed(r))
+			blocking(t, func() { a <- 1 })
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Package debug serves the live pipe topology via HTTP, in the spirit of net/http/pprof.
+//
+// Importing the package enables the registry of package pipe, and registers a handler
+// at /debug/pipe/ of http.DefaultServeMux:
+//
+//	import _ "github.com/hsfzxjy/pipe/debug"
+//
+// Only nodes created after the registry is enabled are listed.
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hsfzxjy/pipe"
+)
+
+func init() {
+	pipe.EnableRegistry(true)
+	http.Handle("/debug/pipe/", Handler())
+}
+
+// Handler returns a handler listing live nodes of the pipe topology as a table.
+// The format query parameter selects another output, "dot" for WriteDOT, or "json"
+// for pipe.Nodes encoded in JSON.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		switch format := r.FormValue("format"); format {
+		case "", "text":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			err = WriteTable(w)
+		case "dot":
+			w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+			err = WriteDOT(w)
+		case "json":
+			w.Header().Set("Content-Type", "application/json")
+			err = json.NewEncoder(w).Encode(pipe.Nodes())
+		default:
+			http.Error(w, fmt.Sprintf("unknown format %q", format), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// WriteTable writes live nodes of the pipe topology to w as a table, one node per line.
+// Columns other than ID, KIND, NAME and SOURCES are left empty for non-broadcasters.
+func WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tKIND\tNAME\tLISTENERS\tACTIVE\tSTARVED\tMAX LAG\tRECEIVED\tDELIVERED\tSOURCES")
+	for _, n := range pipe.Nodes() {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t", n.ID, n.Kind, n.Name)
+		if s := n.Stats; s != nil {
+			fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%d\t%d\t", s.Listeners, s.Active, s.Starved, s.MaxLag(), s.Received, s.Delivered)
+		} else {
+			fmt.Fprint(tw, "\t\t\t\t\t\t")
+		}
+		fmt.Fprintln(tw, joinIDs(n.Sources))
+	}
+	return tw.Flush()
+}
+
+func joinIDs(ids []uint64) string {
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = strconv.FormatUint(id, 10)
+	}
+	return strings.Join(strs, ",")
+}
+
+// WriteDOT writes live nodes of the pipe topology to w as a Graphviz digraph.
+// An edge points from a node to the node it sends values to.
+func WriteDOT(w io.Writer) error {
+	nodes := pipe.Nodes()
+	var b strings.Builder
+	b.WriteString("digraph pipe {\n\tnode [shape=box];\n")
+	for _, n := range nodes {
+		label := n.Kind
+		if n.Name != "" {
+			label += "\n" + n.Name
+		}
+		if s := n.Stats; s != nil {
+			label += fmt.Sprintf("\nlisteners=%d lag=%d", s.Listeners, s.MaxLag())
+		}
+		fmt.Fprintf(&b, "\tn%d [label=%q];\n", n.ID, label)
+	}
+	for _, n := range nodes {
+		for _, src := range n.Sources {
+			fmt.Fprintf(&b, "\tn%d -> n%d;\n", src, n.ID)
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
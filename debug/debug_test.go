@@ -0,0 +1,73 @@
+package debug_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hsfzxjy/pipe"
+	"github.com/hsfzxjy/pipe/debug"
+	"github.com/stretchr/testify/assert"
+)
+
+func get(t *testing.T, url string) string {
+	w := httptest.NewRecorder()
+	debug.Handler().ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+	assert.Equal(t, 200, w.Code)
+	body, err := io.ReadAll(w.Body)
+	assert.NoError(t, err)
+	return string(body)
+}
+
+func TestHandler(t *testing.T) {
+	c := pipe.NewController[int](pipe.WithName[int]("prices"))
+	m := pipe.Map[int](c, func(x int) string { return fmt.Sprint(x) })
+	in, cancel := m.Listen()
+	out := pipe.ConvergeN(in)
+	defer func() {
+		cancel()
+		assert.NoError(t, c.CloseAndWait(context.Background()))
+		for range out {
+		}
+	}()
+
+	var nodes []pipe.NodeInfo
+	assert.NoError(t, json.Unmarshal([]byte(get(t, "/debug/pipe/?format=json")), &nodes))
+	ids := make(map[string]uint64)
+	for _, n := range nodes {
+		ids[n.Kind] = n.ID
+	}
+	assert.Contains(t, ids, "Controller")
+	assert.Contains(t, ids, "Map")
+	assert.Contains(t, ids, "ConvergeN")
+
+	table := get(t, "/debug/pipe/")
+	assert.True(t, strings.HasPrefix(table, "ID"))
+	assert.Contains(t, table, "prices")
+
+	dot := get(t, "/debug/pipe/?format=dot")
+	assert.Contains(t, dot, "digraph pipe {")
+	assert.Contains(t, dot, fmt.Sprintf("n%d -> n%d;", ids["Controller"], ids["Map"]))
+	assert.Contains(t, dot, fmt.Sprintf("n%d -> n%d;", ids["Map"], ids["ConvergeN"]))
+}
+
+func TestWriteDOT(t *testing.T) {
+	c := pipe.NewController[int](pipe.WithName[int]("unused"))
+	c.Sink()
+	defer c.Close()
+	var buf bytes.Buffer
+	assert.NoError(t, debug.WriteDOT(&buf))
+	assert.Contains(t, buf.String(), `\nunused\n`)
+	assert.True(t, strings.HasSuffix(buf.String(), "}\n"))
+}
+
+func TestUnknownFormat(t *testing.T) {
+	w := httptest.NewRecorder()
+	debug.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/debug/pipe/?format=xml", nil))
+	assert.Equal(t, 400, w.Code)
+}
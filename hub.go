@@ -0,0 +1,183 @@
+package pipe
+
+import (
+	"fmt"
+	"sync"
+)
+
+type hubTopic[T any] struct {
+	sink chan T
+	b    broadcaster[T]
+	// listeners is the number of listeners bound via the hub, and published tells
+	// whether any value was routed to the topic, both protected by Hub.mu
+	listeners int
+	published bool
+}
+
+// A Hub routes values from an upstream channel to per-key broadcasters, so that
+// listeners of a key only receive values for that key. Each key memorizes its latest value
+// like BroadcasterM, which is replayed to newly registered listeners of that key.
+// Routing a value costs O(1) regardless of the number of listeners.
+type Hub[K comparable, T any] struct {
+	key  func(T) K
+	opts []Option[T]
+
+	mu     sync.Mutex
+	topics map[K]*hubTopic[T]
+	// err is set once the hub stopped routing, protected by mu
+	err error
+
+	detachOnce sync.Once
+	detachCh   chan struct{}
+	routerDone chan struct{}
+}
+
+// NewHub returns a Hub that routes each value x from upstream to the broadcaster of key(x).
+// opts are applied to every per-key broadcaster. Broadcasters are created on demand, each
+// costing a goroutine. The broadcaster of a key that has seen a value lives as long as the hub,
+// so that its latest value can be replayed. Otherwise it is freed once its last listener is canceled.
+func NewHub[K comparable, T any](upstream <-chan T, key func(T) K, opts ...Option[T]) *Hub[K, T] {
+	h := &Hub[K, T]{
+		key:        key,
+		opts:       opts,
+		topics:     make(map[K]*hubTopic[T]),
+		detachCh:   make(chan struct{}),
+		routerDone: make(chan struct{}),
+	}
+	unregister := register(&node{
+		kind:  "Hub",
+		reads: func() []chanID { return []chanID{chanIDOf(upstream)} },
+		writes: func() []chanID {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			writes := make([]chanID, 0, len(h.topics))
+			for _, t := range h.topics {
+				writes = append(writes, chanIDOf(t.sink))
+			}
+			return writes
+		},
+	})
+	go h.route(upstream, unregister)
+	return h
+}
+
+func (h *Hub[K, T]) route(upstream <-chan T, unregister func()) {
+	defer unregister()
+	defer close(h.routerDone)
+	for {
+		select {
+		case <-h.detachCh:
+			h.stop(ErrDetached)
+			return
+		case x, ok := <-upstream:
+			if !ok {
+				h.stop(ErrClosed)
+				return
+			}
+			k := h.key(x)
+			h.mu.Lock()
+			t := h.topic(k)
+			t.published = true
+			h.mu.Unlock()
+			// sending to a live broadcaster never blocks
+			t.sink <- x
+		}
+	}
+}
+
+// stop shuts down all broadcasters for reason err.
+func (h *Hub[K, T]) stop(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.err = err
+	for _, t := range h.topics {
+		t.shutdown(err)
+	}
+}
+
+func (t *hubTopic[T]) shutdown(err error) {
+	if err == ErrClosed {
+		close(t.sink)
+	} else {
+		t.b.detach()
+	}
+}
+
+// topic returns the topic of k, creating it if not exists. h.mu should be held.
+func (h *Hub[K, T]) topic(k K) *hubTopic[T] {
+	if t, ok := h.topics[k]; ok {
+		return t
+	}
+	t := &hubTopic[T]{sink: make(chan T)}
+	t.b.init(t.sink, nil, h.opts)
+	t.b.kind = "HubKey"
+	if t.b.name == "" {
+		t.b.name = fmt.Sprint(k)
+	} else {
+		t.b.name += "/" + fmt.Sprint(k)
+	}
+	t.b.replay = 1
+	t.b.ensureInit()
+	if h.err != nil {
+		t.shutdown(h.err)
+	}
+	h.topics[k] = t
+	return t
+}
+
+// BindKey registers out as a new listener of key k. The latest value of k, if any, is fed first.
+// It returns a function for unregistering.
+func (h *Hub[K, T]) BindKey(k K, out chan<- T) (cancel func()) {
+	h.mu.Lock()
+	t := h.topic(k)
+	t.listeners++
+	h.mu.Unlock()
+	return h.release(k, t, t.b.Bind(out))
+}
+
+// release wraps cancel of a listener of t, so that t is freed after its last listener
+// is canceled, if no value has been routed to it.
+func (h *Hub[K, T]) release(k K, t *hubTopic[T], cancel func()) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			t.listeners--
+			if t.listeners > 0 || t.published || h.topics[k] != t {
+				return
+			}
+			delete(h.topics, k)
+			if h.err == nil {
+				// otherwise it has been shut down by stop
+				close(t.sink)
+			}
+		})
+	}
+}
+
+// ListenKey creates a new output channel and registers it as a new listener of key k.
+// The output channel and corresponding canceller is returned.
+func (h *Hub[K, T]) ListenKey(k K) (<-chan T, func()) {
+	out := make(chan T)
+	return out, h.BindKey(k, out)
+}
+
+// Current returns the latest value of key k. ok is false if no value of k has shown up.
+func (h *Hub[K, T]) Current(k K) (value T, ok bool) {
+	h.mu.Lock()
+	t, ok := h.topics[k]
+	h.mu.Unlock()
+	if !ok {
+		return
+	}
+	return t.b.memorized()
+}
+
+// Detach prematurely detaches the hub from the upstream channel.
+// No more values from upstream channel would be routed, and all listeners are closed.
+func (h *Hub[K, T]) Detach() {
+	h.detachOnce.Do(func() { close(h.detachCh) })
+	<-h.routerDone
+}
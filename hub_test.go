@@ -0,0 +1,91 @@
+package pipe_test
+
+import (
+	"testing"
+
+	"github.com/hsfzxjy/pipe"
+	"github.com/stretchr/testify/assert"
+)
+
+type reading struct {
+	device string
+	value  int
+}
+
+func TestHub(t *testing.T) {
+	ch := make(chan reading)
+	h := pipe.NewHub(ch, func(r reading) string { return r.device })
+	defer h.Detach()
+	a, _ := h.ListenKey("a")
+	b := make(chan reading)
+	h.BindKey("b", b)
+
+	ch <- reading{"a", 1}
+	ch <- reading{"b", 2}
+	ch <- reading{"c", 3}
+	assert.Equal(t, reading{"a", 1}, <-a)
+	assert.Equal(t, reading{"b", 2}, <-b)
+
+	eventually(t, func() bool {
+		x, ok := h.Current("c")
+		return ok && x == reading{"c", 3}
+	})
+	_, ok := h.Current("d")
+	assert.False(t, ok)
+
+	// the latest value of a key is replayed
+	c, _ := h.ListenKey("c")
+	assert.Equal(t, reading{"c", 3}, <-c)
+	ch <- reading{"c", 4}
+	assert.Equal(t, reading{"c", 4}, <-c)
+	never(t, received(a))
+}
+
+func TestHubFreeIdleKey(t *testing.T) {
+	pipe.EnableRegistry(true)
+	defer pipe.EnableRegistry(false)
+	ch := make(chan int)
+	h := pipe.NewHub(ch, func(x int) int { return x })
+	defer h.Detach()
+
+	idle, cancelIdle := h.ListenKey(-1)
+	assert.NotNil(t, findNode("HubKey", "-1"))
+	cancelIdle()
+	eventually(t, closed(idle))
+	eventually(t, func() bool { return findNode("HubKey", "-1") == nil })
+
+	// a key with a value is kept for replaying
+	busy, cancelBusy := h.ListenKey(1)
+	ch <- 1
+	assert.Equal(t, 1, <-busy)
+	cancelBusy()
+	eventually(t, closed(busy))
+	x, ok := h.Current(1)
+	assert.True(t, ok)
+	assert.Equal(t, 1, x)
+	assert.NotNil(t, findNode("HubKey", "1"))
+}
+
+func TestHubClose(t *testing.T) {
+	ch := make(chan int)
+	h := pipe.NewHub(ch, func(x int) int { return x % 2 })
+	even, _ := h.ListenKey(0)
+	ch <- 2
+	close(ch)
+	assert.Equal(t, 2, <-even)
+	eventually(t, closed(even))
+	// keys created after closing are closed as well
+	odd, _ := h.ListenKey(1)
+	eventually(t, closed(odd))
+}
+
+func TestHubDetach(t *testing.T) {
+	ch := make(chan int)
+	h := pipe.NewHub(ch, func(x int) int { return x })
+	out, _ := h.ListenKey(1)
+	h.Detach()
+	eventually(t, closed(out))
+	// keys created after detaching are closed as well
+	out, _ = h.ListenKey(2)
+	eventually(t, closed(out))
+}
@@ -0,0 +1,104 @@
+package pipe
+
+import (
+	"iter"
+	"reflect"
+)
+
+// All returns an iterator over values from l. Each iteration registers a new listener,
+// which is canceled as soon as the loop ends, either by breaking out or after l closed.
+func All[T any](l Listenable[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		out, cancel := l.Listen()
+		defer cancel()
+		for x := range out {
+			if !yield(x) {
+				return
+			}
+		}
+	}
+}
+
+// Converge2Seq returns an iterator over values from ch1 and ch2, yielding the index of
+// the input (0 or 1) along with each value. The iteration ends after both inputs closed.
+// Unlike Converge2, no goroutine is involved, so breaking out of the loop leaks nothing.
+func Converge2Seq[A, B any](ch1 <-chan A, ch2 <-chan B) iter.Seq2[int, any] {
+	return func(yield func(int, any) bool) {
+		for ch1 != nil || ch2 != nil {
+			var i int
+			var x any
+			select {
+			case x1, ok := <-ch1:
+				if !ok {
+					ch1 = nil
+					continue
+				}
+				i, x = 0, x1
+			case x2, ok := <-ch2:
+				if !ok {
+					ch2 = nil
+					continue
+				}
+				i, x = 1, x2
+			}
+			if !yield(i, x) {
+				return
+			}
+		}
+	}
+}
+
+// Converge3Seq is similar to Converge2Seq, but iterates over three channels.
+func Converge3Seq[A, B, C any](ch1 <-chan A, ch2 <-chan B, ch3 <-chan C) iter.Seq2[int, any] {
+	return func(yield func(int, any) bool) {
+		for ch1 != nil || ch2 != nil || ch3 != nil {
+			var i int
+			var x any
+			select {
+			case x1, ok := <-ch1:
+				if !ok {
+					ch1 = nil
+					continue
+				}
+				i, x = 0, x1
+			case x2, ok := <-ch2:
+				if !ok {
+					ch2 = nil
+					continue
+				}
+				i, x = 1, x2
+			case x3, ok := <-ch3:
+				if !ok {
+					ch3 = nil
+					continue
+				}
+				i, x = 2, x3
+			}
+			if !yield(i, x) {
+				return
+			}
+		}
+	}
+}
+
+// ConvergeNSeq is similar to Converge2Seq, but iterates over arbitary number of channels.
+// Each of chans should be of type <-chan T for some T.
+func ConvergeNSeq(chans ...any) iter.Seq2[int, any] {
+	cases := selectCases(chans)
+	return func(yield func(int, any) bool) {
+		// cases are modified during iteration
+		cases := append([]reflect.SelectCase(nil), cases...)
+		n := len(cases)
+		for n > 0 {
+			i, x, ok := reflect.Select(cases)
+			if !ok {
+				n--
+				cases[i].Chan = reflect.Zero(cases[i].Chan.Type())
+				continue
+			}
+			if !yield(i, x.Interface()) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package pipe_test
+
+import (
+	"testing"
+
+	"github.com/hsfzxjy/pipe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllBreak(t *testing.T) {
+	c := pipe.NewController[int]()
+	s := &spy[int]{Listenable: c}
+	go func() {
+		eventually(t, func() bool { return s.active.Load() == 1 })
+		for i := 1; i <= 3; i++ {
+			c.Send(i)
+		}
+	}()
+	var result []int
+	for x := range pipe.All[int](s) {
+		result = append(result, x)
+		if x == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []int{1, 2}, result)
+	assert.EqualValues(t, 0, s.active.Load())
+}
+
+func TestAllClose(t *testing.T) {
+	ch := make(chan int)
+	s := &spy[int]{Listenable: pipe.BroadcastM(ch, 0)}
+	go func() {
+		eventually(t, func() bool { return s.active.Load() == 1 })
+		ch <- 1
+		close(ch)
+	}()
+	var result []int
+	// the memorized value is replayed first
+	for x := range pipe.All[int](s) {
+		result = append(result, x)
+	}
+	assert.Equal(t, []int{0, 1}, result)
+}
+
+func TestAllDerived(t *testing.T) {
+	c := pipe.NewControllerM(1)
+	m := pipe.MapM[int](c, func(x int) int { return x * 10 })
+	for x := range m.(pipe.Iterable[int]).All() {
+		assert.Equal(t, 10, x)
+		break
+	}
+}
+
+func TestConvergeSeq(t *testing.T) {
+	a := make(chan int)
+	b := make(chan string)
+	c := make(chan float32)
+	go func() {
+		a <- 42
+		b <- "foo"
+		c <- 3.14
+		close(a)
+		close(b)
+		close(c)
+	}()
+	type pair struct {
+		i int
+		x any
+	}
+	var result []pair
+	for i, x := range pipe.Converge3Seq(a, b, c) {
+		result = append(result, pair{i, x})
+	}
+	assert.Equal(t, []pair{{0, 42}, {1, "foo"}, {2, float32(3.14)}}, result)
+
+	a2 := make(chan int)
+	b2 := make(chan string)
+	go func() {
+		b2 <- "foo"
+		a2 <- 42
+		close(a2)
+		close(b2)
+	}()
+	result = nil
+	for i, x := range pipe.Converge2Seq(a2, b2) {
+		result = append(result, pair{i, x})
+	}
+	assert.Equal(t, []pair{{1, "foo"}, {0, 42}}, result)
+}
+
+func TestConvergeNSeqBreak(t *testing.T) {
+	a := make(chan int)
+	b := make(chan int)
+	go func() { a <- 1 }()
+	seq := pipe.ConvergeNSeq(a, b)
+	for i, x := range seq {
+		assert.Equal(t, 0, i)
+		assert.Equal(t, 1, x)
+		break
+	}
+	// the sequence can be iterated again
+	go func() { b <- 2 }()
+	for i, x := range seq {
+		assert.Equal(t, 1, i)
+		assert.Equal(t, 2, x)
+		break
+	}
+}
@@ -0,0 +1,254 @@
+package pipe
+
+import "sync"
+
+// mergeGroupSize is the number of inputs a merge group selects over.
+const mergeGroupSize = 8
+
+type mergeOp[T any] struct {
+	slot int
+	ch   <-chan T
+	// ack, if not nil, is closed after the op applied
+	ack chan struct{}
+}
+
+// mergeGroup forwards values from at most mergeGroupSize inputs in its own goroutine.
+type mergeGroup[T any] struct {
+	// used and n are protected by Merger.mu
+	used [mergeGroupSize]bool
+	n    int
+	// ops are pending changes to the inputs, protected by Merger.mu
+	ops []mergeOp[T]
+	// wake signals that ops are not empty
+	wake chan struct{}
+}
+
+type mergeSlot[T any] struct {
+	g    *mergeGroup[T]
+	slot int
+}
+
+// A Merger merges values from a dynamic set of channels with the same element type
+// into a single output channel, without reflection.
+// Inputs are partitioned into groups of 8, each served by a goroutine with a fixed-arity select.
+type Merger[T any] struct {
+	out  chan T
+	done chan struct{}
+	wg   sync.WaitGroup
+
+	mu     sync.Mutex
+	groups []*mergeGroup[T]
+	inputs map[<-chan T]mergeSlot[T]
+	sealed bool
+	// closed indicates done was closed
+	closed bool
+	// unregister removes the merger from the registry
+	unregister func()
+}
+
+// NewMerger returns a Merger with initial inputs chans.
+func NewMerger[T any](chans ...<-chan T) *Merger[T] {
+	return newMerger("Merger", chans)
+}
+
+func newMerger[T any](kind string, chans []<-chan T) *Merger[T] {
+	m := &Merger[T]{
+		out:    make(chan T),
+		done:   make(chan struct{}),
+		inputs: make(map[<-chan T]mergeSlot[T]),
+	}
+	m.unregister = register(&node{
+		kind: kind,
+		reads: func() []chanID {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			reads := make([]chanID, 0, len(m.inputs))
+			for ch := range m.inputs {
+				reads = append(reads, chanIDOf(ch))
+			}
+			return reads
+		},
+		writes: func() []chanID { return []chanID{chanIDOf(m.out)} },
+	})
+	for _, ch := range chans {
+		m.Add(ch)
+	}
+	return m
+}
+
+// Merge merges values from chans into the returned channel, which will be closed
+// after all of chans closed. Use NewMerger if inputs should be added or removed later.
+func Merge[T any](chans ...<-chan T) <-chan T {
+	m := newMerger("Merge", chans)
+	m.Close()
+	return m.Out()
+}
+
+// Out returns the output channel.
+func (m *Merger[T]) Out() <-chan T { return m.out }
+
+// Add adds ch as a new input. It returns false if ch was already added,
+// or the output channel was closed.
+func (m *Merger[T]) Add(ch <-chan T) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return false
+	}
+	if _, ok := m.inputs[ch]; ok {
+		return false
+	}
+	var g *mergeGroup[T]
+	for _, g2 := range m.groups {
+		if g2.n < mergeGroupSize {
+			g = g2
+			break
+		}
+	}
+	if g == nil {
+		g = &mergeGroup[T]{wake: make(chan struct{}, 1)}
+		m.groups = append(m.groups, g)
+		m.wg.Add(1)
+		go g.run(m)
+	}
+	slot := 0
+	for g.used[slot] {
+		slot++
+	}
+	g.used[slot] = true
+	g.n++
+	m.inputs[ch] = mergeSlot[T]{g, slot}
+	m.push(g, mergeOp[T]{slot: slot, ch: ch})
+	return true
+}
+
+// Remove removes ch from inputs, which will no longer be drained after Remove returns.
+// A value already received from ch may still be sent to the output channel.
+// It returns false if ch is not an input.
+func (m *Merger[T]) Remove(ch <-chan T) bool {
+	m.mu.Lock()
+	s, ok := m.inputs[ch]
+	if !ok {
+		m.mu.Unlock()
+		return false
+	}
+	ack := make(chan struct{})
+	m.drop(ch, s)
+	m.push(s.g, mergeOp[T]{slot: s.slot, ack: ack})
+	m.mu.Unlock()
+	select {
+	case <-ack:
+	case <-m.done:
+	}
+	return true
+}
+
+// Close seals the merger, so that the output channel will be closed as soon as
+// no inputs remain, either closed or removed.
+func (m *Merger[T]) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sealed = true
+	m.tryFinish()
+}
+
+// push queues op for g, m.mu should be held.
+func (m *Merger[T]) push(g *mergeGroup[T], op mergeOp[T]) {
+	g.ops = append(g.ops, op)
+	select {
+	case g.wake <- struct{}{}:
+	default:
+	}
+}
+
+// drop frees the slot of ch, m.mu should be held.
+func (m *Merger[T]) drop(ch <-chan T, s mergeSlot[T]) {
+	delete(m.inputs, ch)
+	s.g.used[s.slot] = false
+	s.g.n--
+	m.tryFinish()
+}
+
+// tryFinish stops all groups and closes the output channel if the merger is sealed
+// and no inputs remain, m.mu should be held.
+func (m *Merger[T]) tryFinish() {
+	if m.closed || !m.sealed || len(m.inputs) > 0 {
+		return
+	}
+	m.closed = true
+	close(m.done)
+	go func() {
+		m.wg.Wait()
+		close(m.out)
+		m.unregister()
+	}()
+}
+
+// inputClosed is called by g when ch in slot closed.
+func (m *Merger[T]) inputClosed(g *mergeGroup[T], slot int, ch <-chan T) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.inputs[ch]; ok && s.g == g && s.slot == slot {
+		m.drop(ch, s)
+	}
+}
+
+func (g *mergeGroup[T]) run(m *Merger[T]) {
+	defer m.wg.Done()
+	var chans [mergeGroupSize]<-chan T
+	apply := func() {
+		m.mu.Lock()
+		ops := g.ops
+		g.ops = nil
+		m.mu.Unlock()
+		for _, op := range ops {
+			chans[op.slot] = op.ch
+			if op.ack != nil {
+				close(op.ack)
+			}
+		}
+	}
+	for {
+		var i int
+		var x T
+		var ok bool
+		select {
+		case <-m.done:
+			return
+		case <-g.wake:
+			apply()
+			continue
+		case x, ok = <-chans[0]:
+			i = 0
+		case x, ok = <-chans[1]:
+			i = 1
+		case x, ok = <-chans[2]:
+			i = 2
+		case x, ok = <-chans[3]:
+			i = 3
+		case x, ok = <-chans[4]:
+			i = 4
+		case x, ok = <-chans[5]:
+			i = 5
+		case x, ok = <-chans[6]:
+			i = 6
+		case x, ok = <-chans[7]:
+			i = 7
+		}
+		if !ok {
+			ch := chans[i]
+			chans[i] = nil
+			m.inputClosed(g, i, ch)
+			continue
+		}
+	SEND:
+		select {
+		case m.out <- x:
+		case <-g.wake:
+			apply()
+			goto SEND
+		case <-m.done:
+			return
+		}
+	}
+}
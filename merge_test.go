@@ -0,0 +1,61 @@
+package pipe_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/hsfzxjy/pipe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	const n = 40
+	chans := make([]<-chan int, n)
+	for i := range chans {
+		ch := make(chan int)
+		chans[i] = ch
+		go func(i int) {
+			ch <- i
+			ch <- i + n
+			close(ch)
+		}(i)
+	}
+	var got []int
+	for x := range pipe.Merge(chans...) {
+		got = append(got, x)
+	}
+	sort.Ints(got)
+	assert.Len(t, got, 2*n)
+	for i, x := range got {
+		assert.Equal(t, i, x)
+	}
+}
+
+func TestMerge0(t *testing.T) {
+	r := pipe.Merge[int]()
+	_, ok := <-r
+	assert.False(t, ok)
+}
+
+func TestMergerAddRemove(t *testing.T) {
+	a := make(chan int)
+	b := make(chan int)
+	m := pipe.NewMerger[int](a)
+	out := m.Out()
+	a <- 1
+	assert.Equal(t, 1, <-out)
+	assert.True(t, m.Add(b))
+	assert.False(t, m.Add(b))
+	b <- 2
+	assert.Equal(t, 2, <-out)
+	assert.True(t, m.Remove(a))
+	assert.False(t, m.Remove(a))
+	// a is no longer drained
+	blocking(t, func() { a <- 3 })
+	m.Close()
+	b <- 4
+	assert.Equal(t, 4, <-out)
+	close(b)
+	eventually(t, closed(out))
+	assert.False(t, m.Add(a))
+}
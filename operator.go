@@ -0,0 +1,173 @@
+package pipe
+
+import (
+	"iter"
+	"sync"
+)
+
+// lazy[T] is a listenable whose broadcaster is started when the first listener binds,
+// and stopped as soon as the last listener cancels.
+type lazy[T any] struct {
+	// start subscribes to the source and pipes derived values into sink.
+	// It returns a function for unsubscribing, after which sink should be closed.
+	start func(sink chan<- T) (stop func())
+	// memorized indicates whether the broadcaster memorizes the latest value
+	memorized bool
+	// kind describes the broadcaster in the registry
+	kind string
+
+	mu   sync.Mutex
+	b    *broadcaster[T]
+	stop func()
+	refs int
+}
+
+func newLazy[T any](kind string, memorized bool, start func(sink chan<- T) (stop func())) *lazy[T] {
+	return &lazy[T]{start: start, memorized: memorized, kind: kind}
+}
+
+// derive returns a lazy listenable fed by pump, which should read from in until it closes.
+func derive[T, U any](kind string, l Listenable[T], memorized bool, pump func(in <-chan T, sink chan<- U)) *lazy[U] {
+	return newLazy(kind, memorized, func(sink chan<- U) func() {
+		in, cancel := l.Listen()
+		unpipe := registerPipe(chanIDOf(sink), chanIDOf(in))
+		go func() {
+			defer unpipe()
+			defer close(sink)
+			pump(in, sink)
+		}()
+		return cancel
+	})
+}
+
+// lazyM[T] is a memorized lazy listenable.
+type lazyM[T any] struct {
+	*lazy[T]
+	// fallback computes the current value when no listener is bound
+	fallback func() T
+}
+
+// deriveM is similar to derive, but returns a memorized listenable. pump should
+// pass the first value from in, which is replayed by l, to sink.
+func deriveM[T, U any](kind string, l ListenableM[T], fallback func() U, pump func(in <-chan T, sink chan<- U)) *lazyM[U] {
+	return &lazyM[U]{derive[T, U](kind, l, true, pump), fallback}
+}
+
+// Current returns the latest value that the listenable memorizes.
+// If no listener is bound, it is computed from the current value of the source.
+func (l *lazyM[T]) Current() T {
+	l.mu.Lock()
+	b := l.b
+	l.mu.Unlock()
+	if b != nil {
+		if x, ok := b.memorized(); ok {
+			return x
+		}
+	}
+	return l.fallback()
+}
+
+// acquire binds out to the broadcaster, starting it if not started yet.
+// out is bound before the upstream subscription starts, so that values replayed
+// by the source are not missed.
+func (l *lazy[T]) acquire(out chan<- T) (b *broadcaster[T], cancel func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.b != nil && l.b.closeErr() == nil {
+		l.refs++
+		return l.b, l.b.Bind(out)
+	}
+	sink := make(chan T)
+	b = new(broadcaster[T])
+	b.init(sink, nil, nil)
+	b.kind = l.kind
+	if l.memorized {
+		b.replay = 1
+	}
+	b.ensureInit()
+	cancel = b.Bind(out)
+	l.b, l.stop, l.refs = b, l.start(sink), 1
+	return b, cancel
+}
+
+func (l *lazy[T]) release(b *broadcaster[T]) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.b != b {
+		// b has died and been replaced
+		return
+	}
+	l.refs--
+	if l.refs == 0 {
+		l.stop()
+		l.b, l.stop = nil, nil
+	}
+}
+
+// Bind registers out as a new listener, starting the upstream subscription if
+// not started yet. When the returned canceller of the last listener is called,
+// the upstream subscription is canceled.
+func (l *lazy[T]) Bind(out chan<- T) (cancel func()) {
+	b, cancelB := l.acquire(out)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancelB()
+			l.release(b)
+		})
+	}
+}
+
+// Listen creates a new output channel and registers it as a new listener.
+// The output channel and corresponding canceller is returned.
+func (l *lazy[T]) Listen() (<-chan T, func()) {
+	out := make(chan T)
+	return out, l.Bind(out)
+}
+
+// All returns an iterator over values from the listenable, see All.
+func (l *lazy[T]) All() iter.Seq[T] { return All[T](l) }
+
+// Map returns a listenable yielding f(x) for each value x from l.
+// The returned listenable subscribes to l when the first listener binds,
+// and unsubscribes when the last listener cancels.
+func Map[T, U any](l Listenable[T], f func(T) U) Listenable[U] {
+	return derive("Map", l, false, func(in <-chan T, sink chan<- U) {
+		for x := range in {
+			sink <- f(x)
+		}
+	})
+}
+
+// MapM is similar to Map, but l and the returned listenable memorize the latest value.
+func MapM[T, U any](l ListenableM[T], f func(T) U) ListenableM[U] {
+	return deriveM("MapM", l, func() U { return f(l.Current()) }, func(in <-chan T, sink chan<- U) {
+		for x := range in {
+			sink <- f(x)
+		}
+	})
+}
+
+// Filter returns a listenable yielding values from l that satisfy pred.
+// It subscribes to l lazily as Map does.
+func Filter[T any](l Listenable[T], pred func(T) bool) Listenable[T] {
+	return derive("Filter", l, false, func(in <-chan T, sink chan<- T) {
+		for x := range in {
+			if pred(x) {
+				sink <- x
+			}
+		}
+	})
+}
+
+// FilterMap returns a listenable yielding y for each value x from l where y, ok := f(x) and ok is true.
+// It subscribes to l lazily as Map does.
+func FilterMap[T, U any](l Listenable[T], f func(T) (U, bool)) Listenable[U] {
+	return derive("FilterMap", l, false, func(in <-chan T, sink chan<- U) {
+		for x := range in {
+			if y, ok := f(x); ok {
+				sink <- y
+			}
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package pipe
+
+import "time"
+
+func batch[T any](maxSize int, maxWait time.Duration, o timeOptions) func(in <-chan T, sink chan<- []T) {
+	return func(in <-chan T, sink chan<- []T) {
+		var t timer
+		defer t.stop()
+		var values []T
+		flush := func() {
+			t.stop()
+			if len(values) > 0 {
+				sink <- values
+				values = nil
+			}
+		}
+		for {
+			select {
+			case x, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+				values = append(values, x)
+				switch {
+				case maxSize > 0 && len(values) >= maxSize:
+					flush()
+				case maxWait > 0 && !t.active():
+					t.start(o.clock, maxWait)
+				}
+			case <-t.C:
+				t.fired()
+				flush()
+			}
+		}
+	}
+}
+
+// Batch returns a listenable yielding values from l in batches.
+// A batch is yielded once it has maxSize values, or maxWait has passed since its first value arrived.
+// A non-positive maxSize or maxWait disables the corresponding limit, but at least one should be positive.
+// The last partial batch is yielded before the listenable closes.
+// The yielded slices are shared among listeners, and should not be modified.
+// It subscribes to l lazily as Map does.
+func Batch[T any](l Listenable[T], maxSize int, maxWait time.Duration, opts ...TimeOption) Listenable[[]T] {
+	if maxSize <= 0 && maxWait <= 0 {
+		panic("expect a positive maxSize or maxWait for Batch")
+	}
+	return derive("Batch", l, false, batch[T](maxSize, maxWait, newTimeOptions(opts)))
+}
+
+// TumblingWindow returns a listenable yielding values from l received in each
+// non-overlapping window of d. Empty windows are skipped.
+// The last partial window is yielded before the listenable closes.
+// The yielded slices are shared among listeners, and should not be modified.
+// It subscribes to l lazily as Map does.
+func TumblingWindow[T any](l Listenable[T], d time.Duration, opts ...TimeOption) Listenable[[]T] {
+	return SlidingWindow(l, d, d, opts...)
+}
+
+// SlidingWindow returns a listenable yielding, every period of every, the values from l
+// received during the last size, which is rounded up to a multiple of every. Empty windows are skipped.
+// The last partial window is yielded before the listenable closes.
+// The yielded slices are shared among listeners, and should not be modified.
+// It subscribes to l lazily as Map does.
+func SlidingWindow[T any](l Listenable[T], size, every time.Duration, opts ...TimeOption) Listenable[[]T] {
+	if size <= 0 || every <= 0 {
+		panic("expect positive size and every for SlidingWindow")
+	}
+	n := int((size + every - 1) / every)
+	o := newTimeOptions(opts)
+	return derive("SlidingWindow", l, false, func(in <-chan T, sink chan<- []T) {
+		ticker := o.clock.NewTicker(every)
+		defer ticker.Stop()
+		// buckets[0] collects the current period, buckets[i] the i-th previous one
+		buckets := make([][]T, n)
+		flush := func() {
+			var values []T
+			for i := n - 1; i >= 0; i-- {
+				values = append(values, buckets[i]...)
+			}
+			if len(values) > 0 {
+				sink <- values
+			}
+		}
+		for {
+			select {
+			case x, ok := <-in:
+				if !ok {
+					if len(buckets[0]) > 0 {
+						flush()
+					}
+					return
+				}
+				buckets[0] = append(buckets[0], x)
+			case <-ticker.C():
+				flush()
+				copy(buckets[1:], buckets)
+				buckets[0] = nil
+			}
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package pipe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hsfzxjy/pipe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatch(t *testing.T) {
+	clk := newFakeClock()
+	src := newManual[int]()
+	l, _ := pipe.Batch[int](src, 3, 10*time.Millisecond, pipe.WithClock(clk)).Listen()
+	src.ch <- 1
+	src.ch <- 2
+	src.ch <- 3
+	assert.Equal(t, []int{1, 2, 3}, <-l)
+	src.ch <- 4
+	eventually(t, func() bool { return clk.Created() == 2 })
+	clk.Advance(10 * time.Millisecond)
+	assert.Equal(t, []int{4}, <-l)
+	src.ch <- 5
+	close(src.ch)
+	assert.Equal(t, []int{5}, <-l)
+	eventually(t, closed(l))
+}
+
+func TestBatchController(t *testing.T) {
+	c := pipe.NewController[int]()
+	l, _ := pipe.Batch[int](c, 2, 0).Listen()
+	for i := 1; i <= 5; i++ {
+		c.Sink() <- i
+	}
+	close(c.Sink())
+	assert.Equal(t, []int{1, 2}, <-l)
+	assert.Equal(t, []int{3, 4}, <-l)
+	assert.Equal(t, []int{5}, <-l)
+	eventually(t, closed(l))
+}
+
+func TestTumblingWindow(t *testing.T) {
+	clk := newFakeClock()
+	src := newManual[int]()
+	l, _ := pipe.TumblingWindow[int](src, 10*time.Millisecond, pipe.WithClock(clk)).Listen()
+	eventually(t, func() bool { return clk.Created() == 1 })
+	src.ch <- 1
+	src.ch <- 2
+	clk.Advance(10 * time.Millisecond)
+	assert.Equal(t, []int{1, 2}, <-l)
+	src.ch <- 3
+	clk.Advance(10 * time.Millisecond)
+	assert.Equal(t, []int{3}, <-l)
+	src.ch <- 4
+	close(src.ch)
+	assert.Equal(t, []int{4}, <-l)
+	eventually(t, closed(l))
+}
+
+func TestSlidingWindow(t *testing.T) {
+	clk := newFakeClock()
+	src := newManual[int]()
+	l, _ := pipe.SlidingWindow[int](src, 20*time.Millisecond, 10*time.Millisecond, pipe.WithClock(clk)).Listen()
+	eventually(t, func() bool { return clk.Created() == 1 })
+	src.ch <- 1
+	clk.Advance(10 * time.Millisecond)
+	assert.Equal(t, []int{1}, <-l)
+	src.ch <- 2
+	clk.Advance(10 * time.Millisecond)
+	assert.Equal(t, []int{1, 2}, <-l)
+	clk.Advance(10 * time.Millisecond)
+	assert.Equal(t, []int{2}, <-l)
+	close(src.ch)
+	eventually(t, closed(l))
+}
@@ -0,0 +1,129 @@
+package pipe
+
+// indexed is a value from the i-th source of a combining operator.
+// closed is set when the source closes.
+type indexed struct {
+	i      int
+	value  any
+	closed bool
+}
+
+// combineSource erases the element type of a memorized source.
+type combineSource struct {
+	current func() any
+	listen  func(i int, updates chan<- indexed) (cancel func())
+}
+
+// as converts v to T, where v may be a nil interface of T.
+func as[T any](v any) T {
+	x, _ := v.(T)
+	return x
+}
+
+func sourceOf[T any](l ListenableM[T]) combineSource {
+	return combineSource{
+		current: func() any { return l.Current() },
+		listen: func(i int, updates chan<- indexed) func() {
+			in, cancel := l.Listen()
+			unpipe := registerPipe(chanIDOf(updates), chanIDOf(in))
+			go func() {
+				defer unpipe()
+				for x := range in {
+					updates <- indexed{i: i, value: x}
+				}
+				updates <- indexed{i: i, closed: true}
+			}()
+			return cancel
+		},
+	}
+}
+
+func combineLatest[R any](kind string, srcs []combineSource, build func(values []any) R) ListenableM[R] {
+	n := len(srcs)
+	current := func() R {
+		values := make([]any, n)
+		for i, s := range srcs {
+			values[i] = s.current()
+		}
+		return build(values)
+	}
+	l := newLazy(kind, true, func(sink chan<- R) func() {
+		updates := make(chan indexed)
+		cancels := make([]func(), n)
+		for i, s := range srcs {
+			cancels[i] = s.listen(i, updates)
+		}
+		unpipe := registerPipe(chanIDOf(sink), chanIDOf(updates))
+		go func() {
+			defer unpipe()
+			defer close(sink)
+			values := make([]any, n)
+			seeded := make([]bool, n)
+			nSeeded, nAlive := 0, n
+			for nAlive > 0 {
+				u := <-updates
+				switch {
+				case !u.closed:
+					values[u.i] = u.value
+				case seeded[u.i]:
+					nAlive--
+					continue
+				default:
+					// closed before replaying, fall back to the current value
+					nAlive--
+					values[u.i] = srcs[u.i].current()
+				}
+				if !seeded[u.i] {
+					seeded[u.i] = true
+					nSeeded++
+				}
+				if nSeeded == n {
+					sink <- build(values)
+				}
+			}
+		}()
+		return func() {
+			for _, cancel := range cancels {
+				cancel()
+			}
+		}
+	})
+	return &lazyM[R]{l, current}
+}
+
+// CombineLatest2 returns a memorized listenable yielding the latest values of a and b,
+// whenever any of them changes. It is seeded with the current values of a and b,
+// and closes after both a and b closed.
+// It subscribes to a and b lazily as Map does. Use MapM to derive a value from the tuple.
+func CombineLatest2[A, B any](a ListenableM[A], b ListenableM[B]) ListenableM[Tuple2[A, B]] {
+	return combineLatest("CombineLatest2",
+		[]combineSource{sourceOf(a), sourceOf(b)},
+		func(values []any) Tuple2[A, B] {
+			return Tuple2[A, B]{as[A](values[0]), as[B](values[1])}
+		})
+}
+
+// CombineLatest3 is similar to CombineLatest2, but combines three listenables.
+func CombineLatest3[A, B, C any](a ListenableM[A], b ListenableM[B], c ListenableM[C]) ListenableM[Tuple3[A, B, C]] {
+	return combineLatest("CombineLatest3",
+		[]combineSource{sourceOf(a), sourceOf(b), sourceOf(c)},
+		func(values []any) Tuple3[A, B, C] {
+			return Tuple3[A, B, C]{as[A](values[0]), as[B](values[1]), as[C](values[2])}
+		})
+}
+
+// CombineLatestN is similar to CombineLatest2, but combines arbitary number of
+// listenables with the same element type.
+func CombineLatestN[T any](srcs ...ListenableM[T]) ListenableM[[]T] {
+	sources := make([]combineSource, len(srcs))
+	for i, src := range srcs {
+		sources[i] = sourceOf(src)
+	}
+	return combineLatest("CombineLatestN", sources, func(values []any) []T {
+		result := make([]T, len(values))
+		for i, v := range values {
+			result[i] = as[T](v)
+		}
+		return result
+	})
+}
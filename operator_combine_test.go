@@ -0,0 +1,55 @@
+package pipe_test
+
+import (
+	"testing"
+
+	"github.com/hsfzxjy/pipe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombineLatest3(t *testing.T) {
+	online := pipe.NewControllerM(false)
+	authed := pipe.NewControllerM(true)
+	config := pipe.NewControllerM("")
+	ready := pipe.MapM(pipe.CombineLatest3[bool, bool, string](online, authed, config),
+		func(t pipe.Tuple3[bool, bool, string]) bool {
+			return t.V1 && t.V2 && t.V3 != ""
+		})
+	assert.False(t, ready.Current())
+
+	l, cancel := ready.Listen()
+	defer cancel()
+	assert.False(t, <-l)
+	online.Sink() <- true
+	assert.False(t, <-l)
+	config.Sink() <- "cfg"
+	assert.True(t, <-l)
+	assert.True(t, ready.Current())
+
+	l2, cancel2 := ready.Listen()
+	defer cancel2()
+	assert.True(t, <-l2)
+}
+
+func TestCombineLatest2Close(t *testing.T) {
+	a := pipe.NewControllerM(1)
+	b := pipe.NewControllerM("foo")
+	l, _ := pipe.CombineLatest2[int, string](a, b).Listen()
+	assert.Equal(t, pipe.Tuple2[int, string]{1, "foo"}, <-l)
+	close(a.Sink())
+	b.Sink() <- "bar"
+	assert.Equal(t, pipe.Tuple2[int, string]{1, "bar"}, <-l)
+	close(b.Sink())
+	eventually(t, closed(l))
+}
+
+func TestCombineLatestN(t *testing.T) {
+	cs := []*pipe.ControllerM[int]{pipe.NewControllerM(0), pipe.NewControllerM(1)}
+	done := pipe.NewControllerM(2)
+	close(done.Sink())
+	l, cancel := pipe.CombineLatestN[int](cs[0], cs[1], done).Listen()
+	defer cancel()
+	assert.Equal(t, []int{0, 1, 2}, <-l)
+	cs[1].Sink() <- 3
+	assert.Equal(t, []int{0, 3, 2}, <-l)
+}
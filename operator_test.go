@@ -0,0 +1,118 @@
+package pipe_test
+
+import (
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hsfzxjy/pipe"
+	"github.com/stretchr/testify/assert"
+)
+
+// spy counts active listeners of the wrapped listenable.
+type spy[T any] struct {
+	pipe.Listenable[T]
+	active atomic.Int32
+}
+
+func (s *spy[T]) Bind(out chan<- T) func() {
+	s.active.Add(1)
+	cancel := s.Listenable.Bind(out)
+	var done atomic.Bool
+	return func() {
+		if done.CompareAndSwap(false, true) {
+			s.active.Add(-1)
+		}
+		cancel()
+	}
+}
+
+func (s *spy[T]) Listen() (<-chan T, func()) {
+	out := make(chan T)
+	return out, s.Bind(out)
+}
+
+func TestMap(t *testing.T) {
+	c := pipe.NewController[int]()
+	m := pipe.Map[int](c, strconv.Itoa)
+	l, _ := m.Listen()
+	c.Sink() <- 1
+	c.Sink() <- 2
+	close(c.Sink())
+	assert.Equal(t, "1", <-l)
+	assert.Equal(t, "2", <-l)
+	eventually(t, closed(l))
+}
+
+func TestMapMemorizedSource(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		c := pipe.NewControllerM(5)
+		l, cancel := pipe.Map[int](c, strconv.Itoa).Listen()
+		// the current value replayed by the source reaches the first listener
+		assert.Equal(t, "5", <-l)
+		cancel()
+		c.Close()
+	}
+	c := pipe.NewControllerM(6)
+	x, err := pipe.UntilFunc[string](pipe.Map[int](c, strconv.Itoa), func(x string) bool { return x == "6" })
+	assert.NoError(t, err)
+	assert.Equal(t, "6", x)
+}
+
+func TestFilter(t *testing.T) {
+	c := pipe.NewController[int]()
+	f := pipe.Filter[int](c, func(x int) bool { return x%2 == 0 })
+	l, _ := f.Listen()
+	for i := 1; i <= 4; i++ {
+		c.Sink() <- i
+	}
+	close(c.Sink())
+	assert.Equal(t, 2, <-l)
+	assert.Equal(t, 4, <-l)
+	eventually(t, closed(l))
+}
+
+func TestFilterMap(t *testing.T) {
+	c := pipe.NewController[string]()
+	f := pipe.FilterMap[string](c, func(s string) (int, bool) {
+		x, err := strconv.Atoi(s)
+		return x, err == nil
+	})
+	l, _ := f.Listen()
+	c.Sink() <- "1"
+	c.Sink() <- "foo"
+	c.Sink() <- "3"
+	close(c.Sink())
+	assert.Equal(t, 1, <-l)
+	assert.Equal(t, 3, <-l)
+	eventually(t, closed(l))
+}
+
+func TestMapLazy(t *testing.T) {
+	c := pipe.NewController[int]()
+	defer close(c.Sink())
+	s := &spy[int]{Listenable: c}
+	m := pipe.Map[int](s, strconv.Itoa)
+	assert.Equal(t, int32(0), s.active.Load())
+
+	l1, cancel1 := m.Listen()
+	l2, cancel2 := m.Listen()
+	assert.Equal(t, int32(1), s.active.Load())
+	c.Sink() <- 1
+	assert.Equal(t, "1", <-l1)
+	assert.Equal(t, "1", <-l2)
+
+	cancel1()
+	eventually(t, closed(l1))
+	assert.Equal(t, int32(1), s.active.Load())
+	cancel2()
+	eventually(t, closed(l2))
+	assert.Equal(t, int32(0), s.active.Load())
+
+	// subscribes again on demand
+	l3, cancel3 := m.Listen()
+	defer cancel3()
+	assert.Equal(t, int32(1), s.active.Load())
+	c.Sink() <- 2
+	assert.Equal(t, "2", <-l3)
+}
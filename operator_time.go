@@ -0,0 +1,188 @@
+package pipe
+
+import "time"
+
+// passFirst returns a pump that passes the first value through before running pump.
+// It is used by memorized operators, so that the value replayed by the source is not delayed.
+func passFirst[T any](pump func(in <-chan T, sink chan<- T)) func(in <-chan T, sink chan<- T) {
+	return func(in <-chan T, sink chan<- T) {
+		x, ok := <-in
+		if !ok {
+			return
+		}
+		sink <- x
+		pump(in, sink)
+	}
+}
+
+func debounce[T any](d time.Duration, o timeOptions) func(in <-chan T, sink chan<- T) {
+	return func(in <-chan T, sink chan<- T) {
+		var t timer
+		defer t.stop()
+		var pending T
+		for {
+			select {
+			case x, ok := <-in:
+				if !ok {
+					if t.active() {
+						sink <- pending
+					}
+					return
+				}
+				pending = x
+				t.start(o.clock, d)
+			case <-t.C:
+				t.fired()
+				sink <- pending
+			}
+		}
+	}
+}
+
+func throttle[T any](d time.Duration, o timeOptions) func(in <-chan T, sink chan<- T) {
+	if !o.leading && !o.trailing {
+		panic("expect at least one of leading and trailing for Throttle")
+	}
+	return func(in <-chan T, sink chan<- T) {
+		var t timer
+		defer t.stop()
+		var pending T
+		var hasPending bool
+		for {
+			select {
+			case x, ok := <-in:
+				if !ok {
+					if hasPending {
+						sink <- pending
+					}
+					return
+				}
+				if t.active() {
+					if o.trailing {
+						pending, hasPending = x, true
+					}
+					continue
+				}
+				if o.leading {
+					sink <- x
+				} else {
+					pending, hasPending = x, true
+				}
+				t.start(o.clock, d)
+			case <-t.C:
+				t.fired()
+				if hasPending {
+					sink <- pending
+					var zero T
+					pending, hasPending = zero, false
+					t.start(o.clock, d)
+				}
+			}
+		}
+	}
+}
+
+func sample[T any](d time.Duration, o timeOptions) func(in <-chan T, sink chan<- T) {
+	return func(in <-chan T, sink chan<- T) {
+		ticker := o.clock.NewTicker(d)
+		defer ticker.Stop()
+		var latest T
+		var hasLatest bool
+		for {
+			select {
+			case x, ok := <-in:
+				if !ok {
+					return
+				}
+				latest, hasLatest = x, true
+			case <-ticker.C():
+				if hasLatest {
+					sink <- latest
+					var zero T
+					latest, hasLatest = zero, false
+				}
+			}
+		}
+	}
+}
+
+func audit[T any](d time.Duration, o timeOptions) func(in <-chan T, sink chan<- T) {
+	return func(in <-chan T, sink chan<- T) {
+		var t timer
+		defer t.stop()
+		var pending T
+		for {
+			select {
+			case x, ok := <-in:
+				if !ok {
+					if t.active() {
+						sink <- pending
+					}
+					return
+				}
+				pending = x
+				if !t.active() {
+					t.start(o.clock, d)
+				}
+			case <-t.C:
+				t.fired()
+				sink <- pending
+			}
+		}
+	}
+}
+
+// Debounce returns a listenable yielding a value from l only after d has passed
+// without another value arriving. A pending value is yielded before the listenable closes.
+// It subscribes to l lazily as Map does.
+func Debounce[T any](l Listenable[T], d time.Duration, opts ...TimeOption) Listenable[T] {
+	return derive("Debounce", l, false, debounce[T](d, newTimeOptions(opts)))
+}
+
+// DebounceM is similar to Debounce, but memorizes the latest value.
+// The current value of l is yielded immediately to the first listener.
+func DebounceM[T any](l ListenableM[T], d time.Duration, opts ...TimeOption) ListenableM[T] {
+	return deriveM("DebounceM", l, l.Current, passFirst(debounce[T](d, newTimeOptions(opts))))
+}
+
+// Throttle returns a listenable yielding at most one value from l per window of d.
+// By default the first value of a window is yielded, and a new window starts with it.
+// With WithTrailing(true), the latest value received during a window is yielded
+// when the window ends, which also starts a new window. Use WithLeading(false) to
+// yield trailing values only. Throttle panics if both leading and trailing are disabled.
+// It subscribes to l lazily as Map does.
+func Throttle[T any](l Listenable[T], d time.Duration, opts ...TimeOption) Listenable[T] {
+	return derive("Throttle", l, false, throttle[T](d, newTimeOptions(opts)))
+}
+
+// ThrottleM is similar to Throttle, but memorizes the latest value.
+// The current value of l is yielded immediately to the first listener.
+func ThrottleM[T any](l ListenableM[T], d time.Duration, opts ...TimeOption) ListenableM[T] {
+	return deriveM("ThrottleM", l, l.Current, passFirst(throttle[T](d, newTimeOptions(opts))))
+}
+
+// Sample returns a listenable yielding the latest value from l every d,
+// if any value has arrived since the last tick.
+// It subscribes to l lazily as Map does.
+func Sample[T any](l Listenable[T], d time.Duration, opts ...TimeOption) Listenable[T] {
+	return derive("Sample", l, false, sample[T](d, newTimeOptions(opts)))
+}
+
+// SampleM is similar to Sample, but memorizes the latest value.
+// The current value of l is yielded immediately to the first listener.
+func SampleM[T any](l ListenableM[T], d time.Duration, opts ...TimeOption) ListenableM[T] {
+	return deriveM("SampleM", l, l.Current, passFirst(sample[T](d, newTimeOptions(opts))))
+}
+
+// Audit returns a listenable that, when a value arrives from l, waits for d and then
+// yields the latest value received meanwhile. A pending value is yielded before the listenable closes.
+// It subscribes to l lazily as Map does.
+func Audit[T any](l Listenable[T], d time.Duration, opts ...TimeOption) Listenable[T] {
+	return derive("Audit", l, false, audit[T](d, newTimeOptions(opts)))
+}
+
+// AuditM is similar to Audit, but memorizes the latest value.
+// The current value of l is yielded immediately to the first listener.
+func AuditM[T any](l ListenableM[T], d time.Duration, opts ...TimeOption) ListenableM[T] {
+	return deriveM("AuditM", l, l.Current, passFirst(audit[T](d, newTimeOptions(opts))))
+}
@@ -0,0 +1,227 @@
+package pipe_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hsfzxjy/pipe"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeWaiter struct {
+	at, period time.Duration
+	c          chan time.Time
+}
+
+// fakeClock fires timers and tickers only when advanced.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Duration
+	created int
+	waiters map[*fakeWaiter]struct{}
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{waiters: map[*fakeWaiter]struct{}{}}
+}
+
+func (c *fakeClock) add(d, period time.Duration) *fakeWaiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &fakeWaiter{at: c.now + d, period: period, c: make(chan time.Time, 1)}
+	c.waiters[w] = struct{}{}
+	c.created++
+	return w
+}
+
+func (c *fakeClock) remove(w *fakeWaiter) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.waiters[w]
+	delete(c.waiters, w)
+	return ok
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now += d
+	for w := range c.waiters {
+		if w.at > c.now {
+			continue
+		}
+		select {
+		case w.c <- time.Time{}:
+		default:
+		}
+		if w.period == 0 {
+			delete(c.waiters, w)
+		} else {
+			for w.at <= c.now {
+				w.at += w.period
+			}
+		}
+	}
+}
+
+// Created returns the number of timers and tickers ever created.
+func (c *fakeClock) Created() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.created
+}
+
+type fakeTimer struct {
+	c *fakeClock
+	w *fakeWaiter
+}
+
+func (t fakeTimer) C() <-chan time.Time { return t.w.c }
+func (t fakeTimer) Stop() bool          { return t.c.remove(t.w) }
+
+type fakeTicker struct{ fakeTimer }
+
+func (t fakeTicker) Stop() { t.c.remove(t.w) }
+
+func (c *fakeClock) NewTimer(d time.Duration) pipe.Timer {
+	return fakeTimer{c, c.add(d, 0)}
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) pipe.Ticker {
+	return fakeTicker{fakeTimer{c, c.add(d, d)}}
+}
+
+// manual is a listenable whose only listener reads from ch directly,
+// so that sending to ch returns after the listener received the value.
+type manual[T any] struct{ ch chan T }
+
+func newManual[T any]() *manual[T] { return &manual[T]{make(chan T)} }
+
+func (m *manual[T]) Listen() (<-chan T, func()) { return m.ch, func() {} }
+
+func (m *manual[T]) Bind(out chan<- T) func() {
+	go func() {
+		defer close(out)
+		for x := range m.ch {
+			out <- x
+		}
+	}()
+	return func() {}
+}
+
+func empty[T any](ch <-chan T) func() bool {
+	return func() bool {
+		select {
+		case <-ch:
+			return false
+		default:
+			return true
+		}
+	}
+}
+
+func TestDebounce(t *testing.T) {
+	clk := newFakeClock()
+	src := newManual[int]()
+	l, _ := pipe.Debounce[int](src, 10*time.Millisecond, pipe.WithClock(clk)).Listen()
+	src.ch <- 1
+	eventually(t, func() bool { return clk.Created() == 1 })
+	clk.Advance(5 * time.Millisecond)
+	src.ch <- 2
+	eventually(t, func() bool { return clk.Created() == 2 })
+	clk.Advance(5 * time.Millisecond)
+	never(t, func() bool { return !empty(l)() })
+	clk.Advance(5 * time.Millisecond)
+	assert.Equal(t, 2, <-l)
+	src.ch <- 3
+	close(src.ch)
+	assert.Equal(t, 3, <-l)
+	eventually(t, closed(l))
+}
+
+func TestThrottle(t *testing.T) {
+	clk := newFakeClock()
+	src := newManual[int]()
+	l, _ := pipe.Throttle[int](src, 10*time.Millisecond, pipe.WithClock(clk)).Listen()
+	src.ch <- 1
+	assert.Equal(t, 1, <-l)
+	src.ch <- 2
+	eventually(t, func() bool { return clk.Created() == 1 })
+	clk.Advance(10 * time.Millisecond)
+	src.ch <- 3
+	assert.Equal(t, 3, <-l)
+	close(src.ch)
+	eventually(t, closed(l))
+}
+
+func TestThrottleTrailing(t *testing.T) {
+	clk := newFakeClock()
+	src := newManual[int]()
+	l, _ := pipe.Throttle[int](src, 10*time.Millisecond,
+		pipe.WithClock(clk), pipe.WithLeading(false), pipe.WithTrailing(true)).Listen()
+	src.ch <- 1
+	src.ch <- 2
+	src.ch <- 3
+	eventually(t, func() bool { return clk.Created() == 1 })
+	clk.Advance(10 * time.Millisecond)
+	assert.Equal(t, 3, <-l)
+	// a new window started with the trailing value
+	eventually(t, func() bool { return clk.Created() == 2 })
+	src.ch <- 4
+	close(src.ch)
+	assert.Equal(t, 4, <-l)
+	eventually(t, closed(l))
+}
+
+func TestSample(t *testing.T) {
+	clk := newFakeClock()
+	src := newManual[int]()
+	l, _ := pipe.Sample[int](src, 10*time.Millisecond, pipe.WithClock(clk)).Listen()
+	eventually(t, func() bool { return clk.Created() == 1 })
+	src.ch <- 1
+	src.ch <- 2
+	clk.Advance(10 * time.Millisecond)
+	assert.Equal(t, 2, <-l)
+	clk.Advance(10 * time.Millisecond)
+	never(t, func() bool { return !empty(l)() })
+	src.ch <- 3
+	clk.Advance(10 * time.Millisecond)
+	assert.Equal(t, 3, <-l)
+	close(src.ch)
+	eventually(t, closed(l))
+}
+
+func TestAudit(t *testing.T) {
+	clk := newFakeClock()
+	src := newManual[int]()
+	l, _ := pipe.Audit[int](src, 10*time.Millisecond, pipe.WithClock(clk)).Listen()
+	src.ch <- 1
+	eventually(t, func() bool { return clk.Created() == 1 })
+	src.ch <- 2
+	clk.Advance(10 * time.Millisecond)
+	assert.Equal(t, 2, <-l)
+	src.ch <- 3
+	close(src.ch)
+	assert.Equal(t, 3, <-l)
+	eventually(t, closed(l))
+}
+
+func TestDebounceM(t *testing.T) {
+	clk := newFakeClock()
+	c := pipe.NewControllerM(0)
+	d := pipe.DebounceM[int](c, 10*time.Millisecond, pipe.WithClock(clk))
+	assert.Equal(t, 0, d.Current())
+	l, cancel := d.Listen()
+	defer cancel()
+	assert.Equal(t, 0, <-l)
+	c.Sink() <- 1
+	eventually(t, func() bool { return clk.Created() == 1 })
+	assert.Equal(t, 0, d.Current())
+	clk.Advance(10 * time.Millisecond)
+	assert.Equal(t, 1, <-l)
+	assert.Equal(t, 1, d.Current())
+	l2, cancel2 := d.Listen()
+	defer cancel2()
+	assert.Equal(t, 1, <-l2)
+}
@@ -0,0 +1,69 @@
+package pipe
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// route creates n outputs, each backed by a broadcaster, and pipes each value x from in
+// to the key(x)-th output. Values with key(x) out of [0, n), or routed to a canceled
+// output, are dropped. All outputs are closed after in closed.
+func route[T any](kind string, in <-chan T, n int, key func(T) int) ([]<-chan T, []func()) {
+	sinks := make([]chan T, n)
+	outs := make([]<-chan T, n)
+	cancels := make([]func(), n)
+	canceled := make([]atomic.Bool, n)
+	for i := range sinks {
+		sinks[i] = make(chan T)
+		// the listener is bound before routing starts, so no value would be missed
+		var cancel func()
+		outs[i], cancel = Broadcast(sinks[i]).Listen()
+		cancels[i] = func() {
+			canceled[i].Store(true)
+			cancel()
+		}
+	}
+	unregister := registerGoroutine(kind, []chanID{chanIDOf(in)}, chanIDsOf(sinks))
+	go func() {
+		defer unregister()
+		defer func() {
+			for _, sink := range sinks {
+				close(sink)
+			}
+		}()
+		for x := range in {
+			k := key(x)
+			if k < 0 || k >= n || canceled[k].Load() {
+				continue
+			}
+			// sending to a broadcaster never blocks
+			sinks[k] <- x
+		}
+	}()
+	return outs, cancels
+}
+
+// Partition routes each value x from in to the key(x)-th of the n returned channels,
+// which are closed after in closed. Values with key(x) out of [0, n) are dropped.
+// Each output buffers values like a broadcaster, so one slow consumer won't block the others.
+// cancels[i] closes the i-th output and drops values routed to it afterwards, which
+// should be called for outputs not consumed, otherwise their values pile up.
+// Partition panics if n < 1.
+func Partition[T any](in <-chan T, n int, key func(T) int) (outs []<-chan T, cancels []func()) {
+	if n < 1 {
+		panic(fmt.Sprintf("expect n >= 1 for Partition, got %d", n))
+	}
+	return route("Partition", in, n, key)
+}
+
+// Split routes values from in satisfying pred to matched, and the others to unmatched.
+// It buffers values as Partition does, and returns cancellers of both outputs.
+func Split[T any](in <-chan T, pred func(T) bool) (matched, unmatched <-chan T, cancelMatched, cancelUnmatched func()) {
+	outs, cancels := route("Split", in, 2, func(x T) int {
+		if pred(x) {
+			return 0
+		}
+		return 1
+	})
+	return outs[0], outs[1], cancels[0], cancels[1]
+}
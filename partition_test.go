@@ -0,0 +1,83 @@
+package pipe_test
+
+import (
+	"testing"
+
+	"github.com/hsfzxjy/pipe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartition(t *testing.T) {
+	in := make(chan int)
+	outs, _ := pipe.Partition(in, 3, func(x int) int { return x % 3 })
+	assert.Len(t, outs, 3)
+	// nobody reads outputs yet, which should not block the upstream
+	for i := 0; i < 9; i++ {
+		in <- i
+	}
+	close(in)
+	for i, out := range outs {
+		var result []int
+		for x := range out {
+			result = append(result, x)
+		}
+		assert.Equal(t, []int{i, i + 3, i + 6}, result)
+	}
+}
+
+func TestPartitionOutOfRange(t *testing.T) {
+	in := make(chan int)
+	outs, _ := pipe.Partition(in, 2, func(x int) int { return x - 1 })
+	for i := 0; i < 4; i++ {
+		in <- i
+	}
+	close(in)
+	assert.Equal(t, 1, <-outs[0])
+	assert.Equal(t, 2, <-outs[1])
+	for _, out := range outs {
+		_, ok := <-out
+		assert.False(t, ok)
+	}
+}
+
+func TestPartitionCancel(t *testing.T) {
+	in := make(chan int)
+	outs, cancels := pipe.Partition(in, 2, func(x int) int { return x % 2 })
+	cancels[1]()
+	_, ok := <-outs[1]
+	assert.False(t, ok)
+	for i := 0; i < 4; i++ {
+		in <- i
+	}
+	close(in)
+	var result []int
+	for x := range outs[0] {
+		result = append(result, x)
+	}
+	assert.Equal(t, []int{0, 2}, result)
+}
+
+func TestPartitionInvalidN(t *testing.T) {
+	assert.Panics(t, func() { pipe.Partition(make(chan int), 0, func(int) int { return 0 }) })
+}
+
+func TestSplit(t *testing.T) {
+	in := make(chan int)
+	even, odd, _, _ := pipe.Split(in, func(x int) bool { return x%2 == 0 })
+	go func() {
+		for i := 0; i < 6; i++ {
+			in <- i
+		}
+		close(in)
+	}()
+	var result []int
+	for x := range odd {
+		result = append(result, x)
+	}
+	assert.Equal(t, []int{1, 3, 5}, result)
+	result = nil
+	for x := range even {
+		result = append(result, x)
+	}
+	assert.Equal(t, []int{0, 2, 4}, result)
+}
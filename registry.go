@@ -0,0 +1,185 @@
+package pipe
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// chanID identifies a channel in the registry, 0 for nil channels.
+type chanID = uintptr
+
+func chanIDOf(ch any) chanID {
+	v := reflect.ValueOf(ch)
+	if !v.IsValid() || v.IsNil() {
+		return 0
+	}
+	return v.Pointer()
+}
+
+func chanIDsOf[C any](chans []C) []chanID {
+	ids := make([]chanID, len(chans))
+	for i, ch := range chans {
+		ids[i] = chanIDOf(ch)
+	}
+	return ids
+}
+
+// node is a live broadcaster or goroutine in the registry.
+type node struct {
+	id   uint64
+	kind string
+	name string
+	// stats, if not nil, returns statistics of the node, including its output channels
+	stats func() Stats
+	// reads returns channels the node receives from
+	reads func() []chanID
+	// writes returns channels the node sends to, only used if stats is nil
+	writes func() []chanID
+}
+
+type nodeRegistry struct {
+	enabled atomic.Bool
+
+	mu     sync.Mutex
+	lastID uint64
+	nodes  map[uint64]*node
+	// pipes maps a channel to channels forwarded into it by an internal goroutine,
+	// which is transparent in the topology
+	pipes map[chanID][]chanID
+}
+
+var registry = nodeRegistry{
+	nodes: make(map[uint64]*node),
+	pipes: make(map[chanID][]chanID),
+}
+
+// EnableRegistry sets whether broadcasters, controllers, converging goroutines and
+// derived operators created afterwards are registered, so that they can be inspected by Nodes.
+// Registered nodes are unregistered when they die. Importing package pipe/debug enables it.
+func EnableRegistry(enabled bool) { registry.enabled.Store(enabled) }
+
+// register adds n to the registry if enabled. It returns a function for unregistering.
+func register(n *node) (unregister func()) {
+	if !registry.enabled.Load() {
+		return noop
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.lastID++
+	n.id = registry.lastID
+	registry.nodes[n.id] = n
+	return func() {
+		registry.mu.Lock()
+		defer registry.mu.Unlock()
+		delete(registry.nodes, n.id)
+	}
+}
+
+// registerGoroutine registers a goroutine that receives from reads and sends to writes.
+func registerGoroutine(kind string, reads, writes []chanID) (unregister func()) {
+	return register(&node{
+		kind:   kind,
+		reads:  func() []chanID { return reads },
+		writes: func() []chanID { return writes },
+	})
+}
+
+// registerPipe records that an internal goroutine forwards values from channel from
+// into channel to. It returns a function for unregistering.
+func registerPipe(to, from chanID) (unregister func()) {
+	if !registry.enabled.Load() {
+		return noop
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.pipes[to] = append(registry.pipes[to], from)
+	return func() {
+		registry.mu.Lock()
+		defer registry.mu.Unlock()
+		froms := registry.pipes[to]
+		for i, f := range froms {
+			if f == from {
+				froms = append(froms[:i], froms[i+1:]...)
+				break
+			}
+		}
+		if len(froms) == 0 {
+			delete(registry.pipes, to)
+		} else {
+			registry.pipes[to] = froms
+		}
+	}
+}
+
+// NodeInfo describes a live node of the pipe topology, see EnableRegistry.
+type NodeInfo struct {
+	ID uint64
+	// Kind is the constructor of the node, e.g., "Controller" or "ConvergeN"
+	Kind string
+	// Name is set by WithName
+	Name string
+	// Stats is nil for nodes other than broadcasters
+	Stats *Stats
+	// Sources holds IDs of nodes sending values to this node
+	Sources []uint64
+}
+
+// Nodes returns all registered nodes sorted by ID.
+func Nodes() []NodeInfo {
+	registry.mu.Lock()
+	nodes := make([]*node, 0, len(registry.nodes))
+	for _, n := range registry.nodes {
+		nodes = append(nodes, n)
+	}
+	registry.mu.Unlock()
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].id < nodes[j].id })
+
+	infos := make([]NodeInfo, len(nodes))
+	writers := make(map[chanID]uint64)
+	reads := make([][]chanID, len(nodes))
+	// collect without holding the lock, since Stats might block shortly
+	for i, n := range nodes {
+		infos[i] = NodeInfo{ID: n.id, Kind: n.kind, Name: n.name}
+		var writes []chanID
+		if n.stats != nil {
+			s := n.stats()
+			infos[i].Stats = &s
+			writes = s.outs
+		} else {
+			writes = n.writes()
+		}
+		for _, ch := range writes {
+			if ch != 0 {
+				writers[ch] = n.id
+			}
+		}
+		reads[i] = n.reads()
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for i := range infos {
+		seen := make(map[chanID]bool)
+		sources := make(map[uint64]bool)
+		queue := append([]chanID(nil), reads[i]...)
+		for len(queue) > 0 {
+			ch := queue[0]
+			queue = queue[1:]
+			if ch == 0 || seen[ch] {
+				continue
+			}
+			seen[ch] = true
+			if id, ok := writers[ch]; ok && id != infos[i].ID {
+				sources[id] = true
+			}
+			queue = append(queue, registry.pipes[ch]...)
+		}
+		for id := range sources {
+			infos[i].Sources = append(infos[i].Sources, id)
+		}
+		sort.Slice(infos[i].Sources, func(a, b int) bool { return infos[i].Sources[a] < infos[i].Sources[b] })
+	}
+	return infos
+}
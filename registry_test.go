@@ -0,0 +1,57 @@
+package pipe_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hsfzxjy/pipe"
+	"github.com/stretchr/testify/assert"
+)
+
+func findNode(kind, name string) *pipe.NodeInfo {
+	for _, n := range pipe.Nodes() {
+		if n.Kind == kind && n.Name == name {
+			return &n
+		}
+	}
+	return nil
+}
+
+func TestRegistry(t *testing.T) {
+	pipe.EnableRegistry(true)
+	defer pipe.EnableRegistry(false)
+
+	a := pipe.NewController[int](pipe.WithName[int]("registry-a"))
+	b := pipe.NewController[int](pipe.WithName[int]("registry-b"))
+	chA, cancelA := a.Listen()
+	chB, cancelB := b.Listen()
+	out := pipe.Merge(chA, chB)
+	merged := pipe.Broadcast(out, pipe.WithName[int]("registry-merged"))
+	merged.Listen()
+
+	na, nb := findNode("Controller", "registry-a"), findNode("Controller", "registry-b")
+	if !assert.NotNil(t, na) || !assert.NotNil(t, nb) {
+		return
+	}
+	assert.Equal(t, 1, na.Stats.Listeners)
+
+	var merger *pipe.NodeInfo
+	for _, n := range pipe.Nodes() {
+		if n.Kind == "Merge" && len(n.Sources) == 2 && n.Sources[0] == na.ID && n.Sources[1] == nb.ID {
+			merger = &n
+		}
+	}
+	nm := findNode("Broadcast", "registry-merged")
+	if !assert.NotNil(t, merger) || !assert.NotNil(t, nm) {
+		return
+	}
+	assert.Equal(t, []uint64{merger.ID}, nm.Sources)
+
+	cancelA()
+	cancelB()
+	assert.NoError(t, a.CloseAndWait(context.Background()))
+	assert.NoError(t, b.CloseAndWait(context.Background()))
+	eventually(t, func() bool {
+		return findNode("Controller", "registry-a") == nil && findNode("Broadcast", "registry-merged") == nil
+	})
+}
@@ -0,0 +1,116 @@
+package pipe
+
+// Stats is a snapshot of the runtime statistics of a broadcaster.
+type Stats struct {
+	// Listeners is the number of registered listeners, which equals Active + Starved
+	Listeners int
+	// Active is the number of listeners with pending values
+	Active int
+	// Starved is the number of listeners waiting for new values
+	Starved int
+	// Received is the number of values received from the upstream channel,
+	// including those dropped as duplicated
+	Received uint64
+	// Delivered is the number of values sent to listeners
+	Delivered uint64
+	// Lags holds the number of pending values of each listener, active ones first
+	Lags []uint64
+	// Closed indicates the upstream channel closed or the broadcaster was detached.
+	// Only Received and Delivered are valid if Closed is set.
+	Closed bool
+
+	// outs identifies output channels of listeners, for building the topology
+	outs []chanID
+}
+
+// MaxLag returns the largest lag among listeners, or 0 if no listener is registered.
+func (s Stats) MaxLag() uint64 {
+	var max uint64
+	for _, lag := range s.Lags {
+		if lag > max {
+			max = lag
+		}
+	}
+	return max
+}
+
+// Stats returns a snapshot of the runtime statistics of the broadcaster.
+func (b *broadcaster[T]) Stats() Stats {
+	if !b.initialized() {
+		return Stats{}
+	}
+	req := make(chan Stats, 1)
+	select {
+	case b.statsCh <- req:
+		return <-req
+	case <-b.diedCh:
+		return Stats{
+			Received:  b.received.Load(),
+			Delivered: b.delivered.Load(),
+			Closed:    true,
+		}
+	}
+}
+
+// snapshot collects Stats, it must be called from the loop goroutine between selects.
+func (b *broadcaster[T]) snapshot() Stats {
+	s := Stats{
+		Active:    b.activeList.n,
+		Starved:   b.starvedList.n,
+		Received:  b.received.Load(),
+		Delivered: b.delivered.Load(),
+	}
+	s.Listeners = s.Active + s.Starved
+	s.Lags = make([]uint64, 0, s.Listeners)
+	tail := b.buf.Load()
+	if head := b.activeList.root; head != nil {
+		for p := head; ; {
+			s.Lags = append(s.Lags, p.lag(tail))
+			if p = p.next; p == head {
+				break
+			}
+		}
+	}
+	for i := 0; i < s.Starved; i++ {
+		s.Lags = append(s.Lags, 0)
+	}
+	if registry.enabled.Load() {
+		s.outs = make([]chanID, 0, s.Listeners)
+		for _, l := range [2]*listenerList[T]{&b.activeList, &b.starvedList} {
+			if head := l.root; head != nil {
+				for p := head; ; {
+					s.outs = append(s.outs, chanIDOf(p.outCh))
+					if p = p.next; p == head {
+						break
+					}
+				}
+			}
+		}
+	}
+	return s
+}
+
+// lag returns the number of pending values of an active listener.
+func (e *listener[T]) lag(tail *bufNode[T]) uint64 {
+	switch {
+	case e.queue != nil:
+		return uint64(e.queue.len())
+	case e.buf == nil:
+		// the listener was starved, and will be fed with tail
+		return 1
+	default:
+		return tail.seq - e.buf.seq + 1
+	}
+}
+
+// Stats returns a snapshot of the runtime statistics of the underlying broadcaster,
+// or a zero Stats if no listener is bound.
+func (l *lazy[T]) Stats() Stats {
+	l.mu.Lock()
+	b := l.b
+	l.mu.Unlock()
+	if b == nil {
+		return Stats{}
+	}
+	return b.Stats()
+}
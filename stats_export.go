@@ -0,0 +1,118 @@
+package pipe
+
+import (
+	"bufio"
+	"expvar"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A StatsSource provides runtime statistics, e.g., broadcasters and controllers.
+type StatsSource interface {
+	Stats() Stats
+}
+
+// A StatsExporter exports statistics of registered sources in Prometheus text format
+// or via expvar.
+type StatsExporter struct {
+	mu      sync.Mutex
+	sources map[string]StatsSource
+}
+
+// NewStatsExporter returns an empty StatsExporter.
+func NewStatsExporter() *StatsExporter {
+	return &StatsExporter{sources: make(map[string]StatsSource)}
+}
+
+// Register adds s with name, replacing the source previously registered with name.
+func (e *StatsExporter) Register(name string, s StatsSource) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sources[name] = s
+}
+
+// Unregister removes the source registered with name.
+func (e *StatsExporter) Unregister(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.sources, name)
+}
+
+type namedStats struct {
+	name  string
+	stats Stats
+}
+
+// collect takes snapshots of all sources, sorted by name.
+func (e *StatsExporter) collect() []namedStats {
+	e.mu.Lock()
+	sources := make([]namedStats, 0, len(e.sources))
+	srcs := make([]StatsSource, 0, len(e.sources))
+	for name, s := range e.sources {
+		sources = append(sources, namedStats{name: name})
+		srcs = append(srcs, s)
+	}
+	e.mu.Unlock()
+	// Stats might block shortly, so call them without holding the lock
+	for i, s := range srcs {
+		sources[i].stats = s.Stats()
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].name < sources[j].name })
+	return sources
+}
+
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WritePrometheus writes statistics of all sources in Prometheus text format,
+// labelled by their names.
+func (e *StatsExporter) WritePrometheus(w io.Writer) error {
+	all := e.collect()
+	bw := bufio.NewWriter(w)
+	metrics := []struct {
+		name, typ, help string
+		value           func(s Stats) uint64
+	}{
+		{"pipe_listeners", "gauge", "Number of registered listeners.",
+			func(s Stats) uint64 { return uint64(s.Listeners) }},
+		{"pipe_listeners_active", "gauge", "Number of listeners with pending values.",
+			func(s Stats) uint64 { return uint64(s.Active) }},
+		{"pipe_listeners_starved", "gauge", "Number of listeners waiting for new values.",
+			func(s Stats) uint64 { return uint64(s.Starved) }},
+		{"pipe_listener_lag_max", "gauge", "Largest number of pending values among listeners.",
+			Stats.MaxLag},
+		{"pipe_received_total", "counter", "Number of values received from the upstream channel.",
+			func(s Stats) uint64 { return s.Received }},
+		{"pipe_delivered_total", "counter", "Number of values sent to listeners.",
+			func(s Stats) uint64 { return s.Delivered }},
+		{"pipe_closed", "gauge", "Whether the upstream channel closed or the broadcaster was detached.",
+			func(s Stats) uint64 {
+				if s.Closed {
+					return 1
+				}
+				return 0
+			}},
+	}
+	for _, m := range metrics {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.typ)
+		for _, s := range all {
+			fmt.Fprintf(bw, "%s{name=\"%s\"} %d\n", m.name, promLabelEscaper.Replace(s.name), m.value(s.stats))
+		}
+	}
+	return bw.Flush()
+}
+
+// PublishExpvar publishes statistics of all sources as an expvar variable with name,
+// which is a map from source names to Stats. Like expvar.Publish, it panics if
+// name is already published.
+func (e *StatsExporter) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		m := make(map[string]Stats)
+		for _, s := range e.collect() {
+			m[s.name] = s.stats
+		}
+		return m
+	}))
+}
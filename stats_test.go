@@ -0,0 +1,73 @@
+package pipe_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hsfzxjy/pipe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	for _, nSlow := range []int{1, 4} {
+		c := pipe.NewController[int]()
+		assert.Equal(t, pipe.Stats{}, c.Stats())
+		fast, _ := c.Listen()
+		for i := 0; i < nSlow; i++ {
+			c.Listen()
+		}
+		for i := 1; i <= 3; i++ {
+			c.Send(i)
+			assert.Equal(t, i, <-fast)
+		}
+		eventually(t, func() bool {
+			s := c.Stats()
+			return s.Listeners == nSlow+1 &&
+				s.Active == nSlow && s.Starved == 1 &&
+				s.Received == 3 && s.Delivered == 3 &&
+				s.MaxLag() == 3 && len(s.Lags) == nSlow+1
+		})
+		c.Close()
+		eventually(t, func() bool { return c.Stats().Closed })
+	}
+}
+
+func TestStatsDerived(t *testing.T) {
+	c := pipe.NewController[int]()
+	m := pipe.Map[int](c, func(x int) int { return x })
+	assert.Equal(t, pipe.Stats{}, m.(pipe.StatsSource).Stats())
+	_, cancel := m.Listen()
+	defer cancel()
+	eventually(t, func() bool { return m.(pipe.StatsSource).Stats().Listeners == 1 })
+}
+
+func TestStatsExporter(t *testing.T) {
+	c := pipe.NewController[int]()
+	l, _ := c.Listen()
+	c.Send(1)
+	<-l
+	e := pipe.NewStatsExporter()
+	e.Register(`ctl"1`, c)
+	e.Register("gone", c)
+	e.Unregister("gone")
+	eventually(t, func() bool { return c.Stats().Delivered == 1 })
+
+	var sb strings.Builder
+	assert.NoError(t, e.WritePrometheus(&sb))
+	text := sb.String()
+	assert.Contains(t, text, "# TYPE pipe_received_total counter\n")
+	assert.Contains(t, text, `pipe_listeners{name="ctl\"1"} 1`+"\n")
+	assert.Contains(t, text, `pipe_delivered_total{name="ctl\"1"} 1`+"\n")
+	assert.NotContains(t, text, "gone")
+
+	// expvar names should be unique across runs with -count
+	name := fmt.Sprintf("pipe_test_stats_%d", time.Now().UnixNano())
+	e.PublishExpvar(name)
+	var m map[string]pipe.Stats
+	assert.NoError(t, json.Unmarshal([]byte(expvar.Get(name).String()), &m))
+	assert.Equal(t, uint64(1), m[`ctl"1`].Received)
+}
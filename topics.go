@@ -0,0 +1,252 @@
+package pipe
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+	"sync"
+)
+
+type topicEntry[T any] struct {
+	name string
+	sink chan T
+	b    broadcaster[T]
+	// subs is the number of bound subscriptions matching the topic, protected by Topics.mu
+	subs int
+}
+
+// topicSub is a bound listener of a subscription.
+type topicSub[T any] struct {
+	filter []string
+	m      *Merger[T]
+	// entries are matched topics and cancels unregisters listeners of them,
+	// both protected by Topics.mu
+	entries []*topicEntry[T]
+	cancels []func()
+}
+
+// A Topics is a publish/subscribe registry with MQTT-style topic names and filters.
+// Topic names are separated into levels by "/". In a filter, "+" matches exactly one level,
+// and "#", which must be the last level, matches any number of remaining levels.
+//
+// Each topic costs a broadcaster goroutine. Without retaining, a topic is freed as soon as
+// no bound subscription matches it. Retained topics live until Remove or Close.
+type Topics[T any] struct {
+	retain bool
+	opts   []Option[T]
+
+	mu     sync.Mutex
+	topics map[string]*topicEntry[T]
+	subs   map[*topicSub[T]]struct{}
+	closed bool
+}
+
+// NewTopics returns an empty Topics. If retain is true, the latest value of each topic is
+// memorized and fed to subsequent subscribers, as BroadcasterM does. opts are applied to
+// the broadcaster of every topic.
+func NewTopics[T any](retain bool, opts ...Option[T]) *Topics[T] {
+	return &Topics[T]{
+		retain: retain,
+		opts:   opts,
+		topics: make(map[string]*topicEntry[T]),
+		subs:   make(map[*topicSub[T]]struct{}),
+	}
+}
+
+// Publish broadcasts value to subscribers whose filters match topic, which should
+// contain no wildcards. It returns false if t was closed.
+func (t *Topics[T]) Publish(topic string, value T) bool {
+	if strings.ContainsAny(topic, "+#") {
+		panic(fmt.Sprintf("expect a topic name without wildcards, got %q", topic))
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return false
+	}
+	e, ok := t.topics[topic]
+	if !ok {
+		levels := strings.Split(topic, "/")
+		var matched []*topicSub[T]
+		for sub := range t.subs {
+			if matchTopic(sub.filter, levels) {
+				matched = append(matched, sub)
+			}
+		}
+		if len(matched) == 0 && !t.retain {
+			// nobody would receive the value
+			return true
+		}
+		e = &topicEntry[T]{name: topic, sink: make(chan T)}
+		e.b.init(e.sink, nil, t.opts)
+		e.b.kind = "Topic"
+		if e.b.name == "" {
+			e.b.name = topic
+		} else {
+			e.b.name += "/" + topic
+		}
+		if t.retain {
+			e.b.replay = 1
+		}
+		e.b.ensureInit()
+		t.topics[topic] = e
+		for _, sub := range matched {
+			sub.listen(e)
+		}
+	}
+	// sending to a broadcaster never blocks
+	e.sink <- value
+	return true
+}
+
+// Subscribe returns a listenable yielding values published to topics matching filter.
+// Subscribe panics if filter is malformed.
+func (t *Topics[T]) Subscribe(filter string) Listenable[T] {
+	levels := strings.Split(filter, "/")
+	for i, level := range levels {
+		if level == "#" && i == len(levels)-1 || level == "+" {
+			continue
+		}
+		if strings.ContainsAny(level, "+#") {
+			panic(fmt.Sprintf("malformed topic filter %q", filter))
+		}
+	}
+	return &subscription[T]{t, levels}
+}
+
+// Remove frees topic along with its retained value. Listeners of topic are not affected,
+// and will receive values published to topic later. It returns false if topic does not exist.
+func (t *Topics[T]) Remove(topic string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.topics[topic]
+	if !ok || t.closed {
+		return false
+	}
+	t.free(e)
+	return true
+}
+
+// free deletes e and shuts down its broadcaster. Topics.mu should be held.
+func (t *Topics[T]) free(e *topicEntry[T]) {
+	delete(t.topics, e.name)
+	close(e.sink)
+}
+
+// release is called when a subscription matching e is canceled. Topics.mu should be held.
+func (t *Topics[T]) release(e *topicEntry[T]) {
+	e.subs--
+	if e.subs == 0 && !t.retain && !t.closed && t.topics[e.name] == e {
+		t.free(e)
+	}
+}
+
+// Close closes all topics and subscriptions. Subsequent Publish returns false.
+func (t *Topics[T]) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	t.closed = true
+	for _, e := range t.topics {
+		close(e.sink)
+	}
+	for sub := range t.subs {
+		sub.m.Close()
+	}
+	t.subs = nil
+}
+
+// matchTopic reports whether topic levels match filter levels.
+func matchTopic(filter, topic []string) bool {
+	for i, level := range filter {
+		if level == "#" {
+			return true
+		}
+		if i >= len(topic) || level != "+" && level != topic[i] {
+			return false
+		}
+	}
+	return len(filter) == len(topic)
+}
+
+// listen binds a listener of e, whose output is merged by sub.m. Topics.mu should be held.
+func (sub *topicSub[T]) listen(e *topicEntry[T]) {
+	out, cancel := e.b.Listen()
+	sub.m.Add(out)
+	e.subs++
+	sub.entries = append(sub.entries, e)
+	sub.cancels = append(sub.cancels, cancel)
+}
+
+type subscription[T any] struct {
+	t      *Topics[T]
+	filter []string
+}
+
+// Bind registers out as a new listener of the subscription, which receives values
+// published to matching topics, including topics created later.
+// A canceller is returned for canceling the subscription. When called, out will be
+// unregistered and closed.
+func (s *subscription[T]) Bind(out chan<- T) (cancel func()) {
+	t := s.t
+	sub := &topicSub[T]{filter: s.filter, m: newMerger[T]("Subscription", nil)}
+	t.mu.Lock()
+	if t.closed {
+		sub.m.Close()
+	} else {
+		for name, e := range t.topics {
+			if matchTopic(sub.filter, strings.Split(name, "/")) {
+				sub.listen(e)
+			}
+		}
+		t.subs[sub] = struct{}{}
+	}
+	t.mu.Unlock()
+
+	stop := make(chan struct{})
+	unpipe := registerPipe(chanIDOf(out), chanIDOf(sub.m.Out()))
+	go func() {
+		defer unpipe()
+		defer close(out)
+		for x := range sub.m.Out() {
+			select {
+			case out <- x:
+			case <-stop:
+				for range sub.m.Out() {
+				}
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			t.mu.Lock()
+			delete(t.subs, sub)
+			for _, e := range sub.entries {
+				t.release(e)
+			}
+			cancels := sub.cancels
+			sub.entries, sub.cancels = nil, nil
+			t.mu.Unlock()
+			for _, cancel := range cancels {
+				cancel()
+			}
+			sub.m.Close()
+		})
+	}
+}
+
+// Listen creates a new output channel and registers it as a new listener.
+// The output channel and corresponding canceller is returned.
+func (s *subscription[T]) Listen() (<-chan T, func()) {
+	out := make(chan T)
+	return out, s.Bind(out)
+}
+
+// All returns an iterator over values of the subscription, see All.
+func (s *subscription[T]) All() iter.Seq[T] { return All[T](s) }
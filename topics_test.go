@@ -0,0 +1,116 @@
+package pipe_test
+
+import (
+	"testing"
+
+	"github.com/hsfzxjy/pipe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopicsWildcard(t *testing.T) {
+	ts := pipe.NewTopics[int](false)
+	defer ts.Close()
+	plus, cancelPlus := ts.Subscribe("sensors/+/temp").Listen()
+	defer cancelPlus()
+	hash, cancelHash := ts.Subscribe("sensors/#").Listen()
+	defer cancelHash()
+	exact, cancelExact := ts.Subscribe("sensors/kitchen/temp").Listen()
+	defer cancelExact()
+
+	ts.Publish("sensors/kitchen/temp", 1)
+	assert.Equal(t, 1, <-plus)
+	assert.Equal(t, 1, <-hash)
+	assert.Equal(t, 1, <-exact)
+
+	ts.Publish("sensors/bedroom/humidity", 2)
+	assert.Equal(t, 2, <-hash)
+	ts.Publish("sensors", 3)
+	assert.Equal(t, 3, <-hash)
+	ts.Publish("sensors/bedroom/temp", 4)
+	assert.Equal(t, 4, <-plus)
+	assert.Equal(t, 4, <-hash)
+	ts.Publish("lights/kitchen", 5)
+
+	never(t, func() bool { return received(plus)() || received(hash)() || received(exact)() })
+}
+
+func TestTopicsRetain(t *testing.T) {
+	ts := pipe.NewTopics[int](true)
+	defer ts.Close()
+	ts.Publish("a/x", 1)
+	ts.Publish("a/x", 2)
+	ts.Publish("a/y", 3)
+	ts.Publish("b", 4)
+	out, cancel := ts.Subscribe("a/+").Listen()
+	defer cancel()
+	assert.ElementsMatch(t, []int{2, 3}, []int{<-out, <-out})
+	ts.Publish("a/y", 5)
+	assert.Equal(t, 5, <-out)
+
+	noRetain := pipe.NewTopics[int](false)
+	defer noRetain.Close()
+	noRetain.Publish("a", 1)
+	out2, cancel2 := noRetain.Subscribe("a").Listen()
+	defer cancel2()
+	never(t, received(out2))
+}
+
+func TestTopicsFree(t *testing.T) {
+	pipe.EnableRegistry(true)
+	defer pipe.EnableRegistry(false)
+	ts := pipe.NewTopics[int](false)
+	defer ts.Close()
+
+	// no subscriber, no topic
+	ts.Publish("free/a", 1)
+	assert.Nil(t, findNode("Topic", "free/a"))
+
+	out, cancel := ts.Subscribe("free/+").Listen()
+	ts.Publish("free/a", 2)
+	assert.Equal(t, 2, <-out)
+	assert.NotNil(t, findNode("Topic", "free/a"))
+	cancel()
+	eventually(t, closed(out))
+	eventually(t, func() bool { return findNode("Topic", "free/a") == nil })
+
+	retained := pipe.NewTopics[int](true)
+	defer retained.Close()
+	retained.Publish("free/b", 1)
+	assert.True(t, retained.Remove("free/b"))
+	assert.False(t, retained.Remove("free/b"))
+	eventually(t, func() bool { return findNode("Topic", "free/b") == nil })
+	out2, cancel2 := retained.Subscribe("free/b").Listen()
+	defer cancel2()
+	never(t, received(out2))
+	retained.Publish("free/b", 2)
+	assert.Equal(t, 2, <-out2)
+}
+
+func TestTopicsCancelAndClose(t *testing.T) {
+	ts := pipe.NewTopics[int](false)
+	s := ts.Subscribe("#")
+	out1, cancel1 := s.Listen()
+	out2, _ := s.Listen()
+	ts.Publish("a", 1)
+	assert.Equal(t, 1, <-out1)
+	assert.Equal(t, 1, <-out2)
+
+	cancel1()
+	eventually(t, closed(out1))
+	ts.Publish("b", 2)
+	assert.Equal(t, 2, <-out2)
+
+	ts.Close()
+	eventually(t, closed(out2))
+	assert.False(t, ts.Publish("a", 3))
+	out3, _ := s.Listen()
+	eventually(t, closed(out3))
+}
+
+func TestTopicsMalformed(t *testing.T) {
+	ts := pipe.NewTopics[int](false)
+	defer ts.Close()
+	assert.Panics(t, func() { ts.Subscribe("a/#/b") })
+	assert.Panics(t, func() { ts.Subscribe("a/b+") })
+	assert.Panics(t, func() { ts.Publish("a/+", 1) })
+}
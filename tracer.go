@@ -0,0 +1,93 @@
+package pipe
+
+import (
+	"context"
+	"runtime/pprof"
+	"runtime/trace"
+	"time"
+)
+
+// ListenerID identifies a listener within a traced broadcaster.
+type ListenerID uint64
+
+// A Tracer is notified of lifecycle events of a broadcaster, see WithTracer.
+// Methods may be called concurrently from different goroutines, and should return quickly.
+// Embed NopTracer to implement only a subset of the methods.
+type Tracer interface {
+	// Bind is called when listener id is registered.
+	Bind(id ListenerID)
+	// Cancel is called when the canceller of listener id is called, or its context is done.
+	Cancel(id ListenerID)
+	// Finalize is called when listener id is unregistered and its output channel is closed.
+	Finalize(id ListenerID)
+	// Receive is called when value is received from the upstream channel.
+	Receive(value any)
+	// Deliver is called when value is sent to listener id, latency after it was received.
+	Deliver(id ListenerID, value any, latency time.Duration)
+	// Starve is called when listener id has received all pending values.
+	Starve(id ListenerID)
+	// Close is called when the broadcaster dies, err is ErrClosed or ErrDetached.
+	Close(err error)
+	// Task is called on the executor goroutine to run a select task of the broadcaster.
+	// A traced broadcaster never selects inline, so that every select task goes through Task.
+	// It must call run exactly once.
+	Task(run func())
+}
+
+// NopTracer is a Tracer that does nothing.
+type NopTracer struct{}
+
+func (NopTracer) Bind(ListenerID)                        {}
+func (NopTracer) Cancel(ListenerID)                      {}
+func (NopTracer) Finalize(ListenerID)                    {}
+func (NopTracer) Receive(any)                            {}
+func (NopTracer) Deliver(ListenerID, any, time.Duration) {}
+func (NopTracer) Starve(ListenerID)                      {}
+func (NopTracer) Close(error)                            {}
+func (NopTracer) Task(run func())                        { run() }
+
+// WithTracer makes the broadcaster notify t of its lifecycle events.
+func WithTracer[T any](t Tracer) Option[T] {
+	return func(o *options[T]) { o.tracer = t }
+}
+
+type labeledTracer struct {
+	Tracer
+	region string
+	labels pprof.LabelSet
+}
+
+// LabelTasks returns a Tracer wrapping t, which runs select tasks with pprof labels,
+// in a runtime/trace region named region. labels are key-value pairs as pprof.Labels accepts.
+func LabelTasks(t Tracer, region string, labels ...string) Tracer {
+	return labeledTracer{t, region, pprof.Labels(labels...)}
+}
+
+func (t labeledTracer) Task(run func()) {
+	pprof.Do(context.Background(), t.labels, func(ctx context.Context) {
+		trace.WithRegion(ctx, t.region, func() { t.Tracer.Task(run) })
+	})
+}
+
+// tracedExecutor runs tasks through tracer.Task.
+type tracedExecutor struct {
+	Executor
+	tracer Tracer
+}
+
+func (e tracedExecutor) Execute(task func()) {
+	e.Executor.Execute(func() { e.tracer.Task(task) })
+}
+
+var epoch = time.Now()
+
+// nanotime returns monotonic nanoseconds since epoch.
+func nanotime() int64 { return int64(time.Since(epoch)) }
+
+func (e *listener[T]) traceDeliver(node *bufNode[T]) {
+	var latency time.Duration
+	if node.at != 0 {
+		latency = time.Duration(nanotime() - node.at)
+	}
+	e.tracer.Deliver(e.id, node.value, latency)
+}
@@ -0,0 +1,102 @@
+package pipe_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hsfzxjy/pipe"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder records events as strings.
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+	tasks  int
+}
+
+func (r *recorder) record(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Events() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func (r *recorder) Bind(id pipe.ListenerID)     { r.record("bind %d", id) }
+func (r *recorder) Cancel(id pipe.ListenerID)   { r.record("cancel %d", id) }
+func (r *recorder) Finalize(id pipe.ListenerID) { r.record("finalize %d", id) }
+func (r *recorder) Receive(value any)           { r.record("receive %v", value) }
+func (r *recorder) Starve(id pipe.ListenerID)   { r.record("starve %d", id) }
+func (r *recorder) Close(err error)             { r.record("close %v", err) }
+func (r *recorder) Deliver(id pipe.ListenerID, value any, latency time.Duration) {
+	if latency < 0 {
+		panic("negative latency")
+	}
+	r.record("deliver %d %v", id, value)
+}
+func (r *recorder) Task(run func()) {
+	r.mu.Lock()
+	r.tasks++
+	r.mu.Unlock()
+	run()
+}
+
+func TestTracer(t *testing.T) {
+	var r recorder
+	c := pipe.NewController[int](pipe.WithTracer[int](&r))
+	l, cancel := c.Listen()
+	c.Send(1)
+	assert.Equal(t, 1, <-l)
+	eventually(t, func() bool { return len(r.Events()) == 4 })
+	cancel()
+	eventually(t, closed(l))
+	c.Close()
+	eventually(t, func() bool { return len(r.Events()) == 7 })
+	assert.Equal(t, []string{
+		"bind 1",
+		"receive 1",
+		"deliver 1 1",
+		"starve 1",
+		"cancel 1",
+		"finalize 1",
+		"close " + pipe.ErrClosed.Error(),
+	}, r.Events())
+}
+
+func TestTracerBounded(t *testing.T) {
+	var r recorder
+	ch := make(chan int)
+	b := pipe.Broadcast(ch, pipe.WithTracer[int](&r))
+	l, _ := b.ListenWithOptions(pipe.BindOptions{MaxLag: 1, Overflow: pipe.Disconnect})
+	ch <- 1
+	ch <- 2
+	eventually(t, func() bool { return len(r.Events()) == 4 })
+	assert.Equal(t, []string{"bind 1", "receive 1", "receive 2", "finalize 1"}, r.Events())
+	b.Detach()
+	eventually(t, func() bool { return len(r.Events()) == 5 })
+	assert.Equal(t, "close "+pipe.ErrDetached.Error(), r.Events()[4])
+	// the listener was disconnected before receiving anything
+	eventually(t, closed(l))
+}
+
+func TestLabelTasks(t *testing.T) {
+	var r recorder
+	c := pipe.NewController[int](pipe.WithTracer[int](pipe.LabelTasks(&r, "pipe", "broadcaster", "test")))
+	// a single listener would be served inline if untraced
+	broadcastTo(t, c, 1)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	assert.Positive(t, r.tasks)
+}
+
+func TestNopTracer(t *testing.T) {
+	c := pipe.NewController[int](pipe.WithTracer[int](pipe.NopTracer{}))
+	broadcastTo(t, c, 4)
+}
@@ -0,0 +1,14 @@
+package pipe
+
+// Tuple2 holds two values of possibly different types.
+type Tuple2[A, B any] struct {
+	V1 A
+	V2 B
+}
+
+// Tuple3 holds three values of possibly different types.
+type Tuple3[A, B, C any] struct {
+	V1 A
+	V2 B
+	V3 C
+}
@@ -0,0 +1,132 @@
+package pipe
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+var (
+	// ErrClosed is returned by Until* functions when the upstream channel closed.
+	ErrClosed = errors.New("pipe: upstream closed")
+	// ErrDetached is returned by Until* functions when the broadcaster was detached.
+	ErrDetached = errors.New("pipe: broadcaster detached")
+	// ErrCancelled is returned by Until*Ch functions when the canceller was called.
+	ErrCancelled = errors.New("pipe: cancelled")
+)
+
+// UntilResult is the outcome of UntilCh and UntilFuncCh.
+// Value is the value satisfying the condition if Err is nil, otherwise the zero value.
+type UntilResult[T any] struct {
+	Value T
+	Err   error
+}
+
+// closeErrOf returns why b stopped feeding its listeners.
+func closeErrOf[T any](b Listenable[T]) error {
+	if d, ok := b.(interface{ closeErr() error }); ok {
+		if err := d.closeErr(); err != nil {
+			return err
+		}
+	}
+	return ErrClosed
+}
+
+// checkCurrent reports whether b memorizes a current value satisfying pred.
+// It is only a shortcut: the memorized value is also replayed to the subsequent
+// listener, so there is no window between checking and listening.
+func checkCurrent[T any](b Listenable[T], pred func(T) bool) (T, bool) {
+	switch m := b.(type) {
+	case interface{ memorized() (T, bool) }:
+		if x, ok := m.memorized(); ok && pred(x) {
+			return x, true
+		}
+	case interface{ Current() T }:
+		if x := m.Current(); pred(x) {
+			return x, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// UntilFunc blocks until one of the conditions satisfies:
+// 1) a value from b satisfies pred, which is returned with a nil error;
+// 2) b does not accept new listeners, in which case ErrDetached or ErrClosed is returned.
+// If b memorizes the latest value, it is checked first.
+func UntilFunc[T any, P Listenable[T]](b P, pred func(T) bool) (T, error) {
+	if x, ok := checkCurrent[T](b, pred); ok {
+		return x, nil
+	}
+	out, cancel := b.Listen()
+	defer cancel()
+	for x := range out {
+		if pred(x) {
+			return x, nil
+		}
+	}
+	var zero T
+	return zero, closeErrOf[T](b)
+}
+
+// UntilFuncCh is the asynchronous version of UntilFunc.
+// The returned resultCh yields exactly one result and then closes, when one of the conditions satisfies:
+// 1) a value from b satisfies pred;
+// 2) b does not accept new listeners, in which case Err is ErrDetached or ErrClosed;
+// 3) canceller is called, in which case Err is ErrCancelled.
+func UntilFuncCh[T any, P Listenable[T]](b P, pred func(T) bool) (resultCh <-chan UntilResult[T], canceller func()) {
+	result := make(chan UntilResult[T], 1)
+	if x, ok := checkCurrent[T](b, pred); ok {
+		result <- UntilResult[T]{Value: x}
+		close(result)
+		return result, noop
+	}
+	out, cancel := b.Listen()
+	var cancelled atomic.Bool
+	go func() {
+		defer close(result)
+		defer cancel()
+		for x := range out {
+			if pred(x) {
+				result <- UntilResult[T]{Value: x}
+				return
+			}
+		}
+		if cancelled.Load() {
+			result <- UntilResult[T]{Err: ErrCancelled}
+		} else {
+			result <- UntilResult[T]{Err: closeErrOf[T](b)}
+		}
+	}()
+	return result, func() {
+		cancelled.Store(true)
+		cancel()
+	}
+}
+
+// UntilFuncContext blocks until one of the conditions satisfies:
+// 1) a value from b satisfies pred, which is returned with a nil error;
+// 2) b does not accept new listeners, in which case ErrDetached or ErrClosed is returned;
+// 3) ctx is done, in which case ctx.Err() is returned.
+// If b memorizes the latest value, it is checked first.
+func UntilFuncContext[T any, P Listenable[T]](ctx context.Context, b P, pred func(T) bool) (T, error) {
+	if x, ok := checkCurrent[T](b, pred); ok {
+		return x, nil
+	}
+	out, cancel := b.Listen()
+	defer cancel()
+	var zero T
+	for {
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case x, ok := <-out:
+			if !ok {
+				return zero, closeErrOf[T](b)
+			}
+			if pred(x) {
+				return x, nil
+			}
+		}
+	}
+}
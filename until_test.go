@@ -0,0 +1,91 @@
+package pipe_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hsfzxjy/pipe"
+	"github.com/stretchr/testify/assert"
+)
+
+type phase struct {
+	name  string
+	temps []int
+}
+
+func TestUntilFunc(t *testing.T) {
+	c := pipe.NewControllerM(phase{name: "init"})
+	go func() {
+		c.Sink() <- phase{name: "warming", temps: []int{60}}
+		c.Sink() <- phase{name: "ready", temps: []int{85}}
+	}()
+	x, err := pipe.UntilFunc(c, func(p phase) bool { return p.temps != nil && p.temps[0] > 80 })
+	assert.NoError(t, err)
+	assert.Equal(t, "ready", x.name)
+}
+
+func TestUntilFuncClosed(t *testing.T) {
+	c := pipe.NewController[int]()
+	go func() {
+		c.Sink() <- 1
+		close(c.Sink())
+	}()
+	x, err := pipe.UntilFunc(c, func(x int) bool { return x > 1 })
+	assert.ErrorIs(t, err, pipe.ErrClosed)
+	assert.Zero(t, x)
+}
+
+func TestUntilFuncChCancel(t *testing.T) {
+	c := pipe.NewControllerM(0)
+	result, cancel := pipe.UntilFuncCh(c, func(x int) bool { return x > 1 })
+	never(t, func() bool { return len(result) > 0 })
+	cancel()
+	r := <-result
+	assert.ErrorIs(t, r.Err, pipe.ErrCancelled)
+	_, ok := <-result
+	assert.False(t, ok)
+}
+
+func TestUntilFuncChMatched(t *testing.T) {
+	c := pipe.NewControllerM(0)
+	result, cancel := pipe.UntilFuncCh(c, func(x int) bool { return x > 1 })
+	defer cancel()
+	c.Send(2)
+	r := <-result
+	assert.NoError(t, r.Err)
+	assert.Equal(t, 2, r.Value)
+}
+
+func TestUntilFuncContext(t *testing.T) {
+	c := pipe.NewControllerM(0)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := pipe.UntilFuncContext(ctx, c, func(x int) bool { return x > 1 })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestUntilDetached(t *testing.T) {
+	ch := make(chan int)
+	b := pipe.BroadcastCM(ch, 0)
+	go b.Detach()
+	_, err := b.Until(1)
+	assert.ErrorIs(t, err, pipe.ErrDetached)
+	// listening after detached
+	_, err = b.Until(1)
+	assert.ErrorIs(t, err, pipe.ErrDetached)
+}
+
+func TestUntilCurrentAfterClosed(t *testing.T) {
+	ch := make(chan int)
+	b := pipe.BroadcastCM(ch, 42)
+	ch <- 1
+	close(ch)
+	eventually(t, func() bool {
+		_, err := b.Until(2)
+		return err == pipe.ErrClosed
+	})
+	x, err := b.Until(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, x)
+}
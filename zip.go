@@ -0,0 +1,185 @@
+package pipe
+
+import "reflect"
+
+func zip2[A, B any](ch1 <-chan A, ch2 <-chan B, longest bool) <-chan Tuple2[A, B] {
+	out := make(chan Tuple2[A, B])
+	unregister := registerGoroutine("Zip2", []chanID{chanIDOf(ch1), chanIDOf(ch2)}, []chanID{chanIDOf(out)})
+	go func() {
+		defer unregister()
+		defer close(out)
+		for {
+			var t Tuple2[A, B]
+			// c1 and c2 are set to nil once received in this round
+			c1, c2 := ch1, ch2
+			got := 0
+			for c1 != nil || c2 != nil {
+				select {
+				case x, ok := <-c1:
+					c1 = nil
+					if !ok {
+						if !longest {
+							return
+						}
+						ch1 = nil
+						continue
+					}
+					t.V1 = x
+					got++
+				case x, ok := <-c2:
+					c2 = nil
+					if !ok {
+						if !longest {
+							return
+						}
+						ch2 = nil
+						continue
+					}
+					t.V2 = x
+					got++
+				}
+			}
+			if got == 0 {
+				return
+			}
+			out <- t
+		}
+	}()
+	return out
+}
+
+func zip3[A, B, C any](ch1 <-chan A, ch2 <-chan B, ch3 <-chan C, longest bool) <-chan Tuple3[A, B, C] {
+	out := make(chan Tuple3[A, B, C])
+	unregister := registerGoroutine("Zip3", []chanID{chanIDOf(ch1), chanIDOf(ch2), chanIDOf(ch3)}, []chanID{chanIDOf(out)})
+	go func() {
+		defer unregister()
+		defer close(out)
+		for {
+			var t Tuple3[A, B, C]
+			// c1, c2 and c3 are set to nil once received in this round
+			c1, c2, c3 := ch1, ch2, ch3
+			got := 0
+			for c1 != nil || c2 != nil || c3 != nil {
+				select {
+				case x, ok := <-c1:
+					c1 = nil
+					if !ok {
+						if !longest {
+							return
+						}
+						ch1 = nil
+						continue
+					}
+					t.V1 = x
+					got++
+				case x, ok := <-c2:
+					c2 = nil
+					if !ok {
+						if !longest {
+							return
+						}
+						ch2 = nil
+						continue
+					}
+					t.V2 = x
+					got++
+				case x, ok := <-c3:
+					c3 = nil
+					if !ok {
+						if !longest {
+							return
+						}
+						ch3 = nil
+						continue
+					}
+					t.V3 = x
+					got++
+				}
+			}
+			if got == 0 {
+				return
+			}
+			out <- t
+		}
+	}()
+	return out
+}
+
+func zipN[T any](chans []<-chan T, longest bool) <-chan []T {
+	out := make(chan []T)
+	cases := make([]reflect.SelectCase, len(chans))
+	for i, ch := range chans {
+		cases[i] = reflect.SelectCase{Chan: reflect.ValueOf(ch), Dir: reflect.SelectRecv}
+	}
+	unregister := registerGoroutine("ZipN", chanIDsOf(chans), []chanID{chanIDOf(out)})
+	go func() {
+		defer unregister()
+		defer close(out)
+		closed := make([]bool, len(chans))
+		round := make([]reflect.SelectCase, len(cases))
+		for {
+			values := make([]T, len(cases))
+			pending := 0
+			for i := range cases {
+				round[i] = cases[i]
+				if closed[i] {
+					round[i].Chan = reflect.Value{}
+				} else {
+					pending++
+				}
+			}
+			got := 0
+			for ; pending > 0; pending-- {
+				i, x, ok := reflect.Select(round)
+				round[i].Chan = reflect.Value{}
+				if !ok {
+					if !longest {
+						return
+					}
+					closed[i] = true
+					continue
+				}
+				values[i] = as[T](x.Interface())
+				got++
+			}
+			if got == 0 {
+				return
+			}
+			out <- values
+		}
+	}()
+	return out
+}
+
+// Zip2 pairs values from ch1 and ch2 in lock-step: it waits for one value from each
+// input and emits them together. The returned channel closes when any input closes,
+// and values received in an incomplete round are dropped.
+func Zip2[A, B any](ch1 <-chan A, ch2 <-chan B) <-chan Tuple2[A, B] {
+	return zip2(ch1, ch2, false)
+}
+
+// Zip2Longest is similar to Zip2, but continues until all inputs close,
+// padding closed inputs with zero values.
+func Zip2Longest[A, B any](ch1 <-chan A, ch2 <-chan B) <-chan Tuple2[A, B] {
+	return zip2(ch1, ch2, true)
+}
+
+// Zip3 is similar to Zip2, but zips three channels.
+func Zip3[A, B, C any](ch1 <-chan A, ch2 <-chan B, ch3 <-chan C) <-chan Tuple3[A, B, C] {
+	return zip3(ch1, ch2, ch3, false)
+}
+
+// Zip3Longest is similar to Zip2Longest, but zips three channels.
+func Zip3Longest[A, B, C any](ch1 <-chan A, ch2 <-chan B, ch3 <-chan C) <-chan Tuple3[A, B, C] {
+	return zip3(ch1, ch2, ch3, true)
+}
+
+// ZipN is similar to Zip2, but zips arbitary number of channels with the same element type.
+func ZipN[T any](chans ...<-chan T) <-chan []T {
+	return zipN(chans, false)
+}
+
+// ZipNLongest is similar to Zip2Longest, but zips arbitary number of channels with the same element type.
+func ZipNLongest[T any](chans ...<-chan T) <-chan []T {
+	return zipN(chans, true)
+}
@@ -0,0 +1,119 @@
+package pipe_test
+
+import (
+	"testing"
+
+	"github.com/hsfzxjy/pipe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZip2(t *testing.T) {
+	a := make(chan int)
+	b := make(chan string)
+	z := pipe.Zip2(a, b)
+	go func() {
+		// b is sent first, which should not block the zipper
+		b <- "foo"
+		a <- 1
+		a <- 2
+		b <- "bar"
+		a <- 3
+		close(b)
+	}()
+	assert.Equal(t, pipe.Tuple2[int, string]{1, "foo"}, <-z)
+	assert.Equal(t, pipe.Tuple2[int, string]{2, "bar"}, <-z)
+	_, ok := <-z
+	assert.False(t, ok)
+}
+
+func TestZip2Longest(t *testing.T) {
+	a := make(chan int)
+	b := make(chan string)
+	z := pipe.Zip2Longest(a, b)
+	go func() {
+		a <- 1
+		b <- "foo"
+		close(b)
+		a <- 2
+		a <- 3
+		close(a)
+	}()
+	assert.Equal(t, pipe.Tuple2[int, string]{1, "foo"}, <-z)
+	assert.Equal(t, pipe.Tuple2[int, string]{2, ""}, <-z)
+	assert.Equal(t, pipe.Tuple2[int, string]{3, ""}, <-z)
+	_, ok := <-z
+	assert.False(t, ok)
+}
+
+func TestZip3(t *testing.T) {
+	a := make(chan int)
+	b := make(chan string)
+	c := make(chan float32)
+	z := pipe.Zip3(a, b, c)
+	go func() {
+		c <- 3.14
+		b <- "foo"
+		a <- 42
+		close(c)
+	}()
+	assert.Equal(t, pipe.Tuple3[int, string, float32]{42, "foo", 3.14}, <-z)
+	_, ok := <-z
+	assert.False(t, ok)
+}
+
+func TestZip3Longest(t *testing.T) {
+	a := make(chan int)
+	b := make(chan string)
+	c := make(chan float32)
+	z := pipe.Zip3Longest(a, b, c)
+	go func() {
+		close(a)
+		b <- "foo"
+		c <- 3.14
+		close(c)
+		b <- "bar"
+		close(b)
+	}()
+	assert.Equal(t, pipe.Tuple3[int, string, float32]{0, "foo", 3.14}, <-z)
+	assert.Equal(t, pipe.Tuple3[int, string, float32]{0, "bar", 0}, <-z)
+	_, ok := <-z
+	assert.False(t, ok)
+}
+
+func TestZipN(t *testing.T) {
+	a := make(chan int)
+	b := make(chan int)
+	c := make(chan int)
+	z := pipe.ZipN(a, b, c)
+	go func() {
+		c <- 3
+		a <- 1
+		b <- 2
+		a <- 4
+		b <- 5
+		close(c)
+	}()
+	assert.Equal(t, []int{1, 2, 3}, <-z)
+	_, ok := <-z
+	assert.False(t, ok)
+
+	_, ok = <-pipe.ZipN[int]()
+	assert.False(t, ok)
+}
+
+func TestZipNLongest(t *testing.T) {
+	a := make(chan int)
+	b := make(chan int)
+	z := pipe.ZipNLongest(a, b)
+	go func() {
+		a <- 1
+		b <- 2
+		close(a)
+		b <- 3
+		close(b)
+	}()
+	assert.Equal(t, []int{1, 2}, <-z)
+	assert.Equal(t, []int{0, 3}, <-z)
+	_, ok := <-z
+	assert.False(t, ok)
+}